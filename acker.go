@@ -0,0 +1,90 @@
+package argo
+
+import (
+	"context"
+
+	"github.com/gildas/argo/core"
+)
+
+// Acker acknowledges message deliveries.
+//
+// Stream adapters (Kafka, SQS, AMQP, ...) implement it over their native
+// acknowledgment mechanism; argo calls it after handler completion so
+// consumers get at-least-once delivery semantics without wiring acks into
+// every handler.
+type Acker interface {
+	// Ack reports the message was processed successfully
+	Ack() error
+	// Nack reports the message failed; requeue hints the broker to redeliver it
+	Nack(requeue bool) error
+}
+
+// Delivery is a payload together with the Acker of its transport message
+type Delivery struct {
+	Payload []byte
+	Acker   Acker
+}
+
+// AckingPipeline processes acknowledged deliveries one at a time.
+//
+// Each delivery is decoded through the registry, flows through the stages
+// and the sink, then its Acker is called: Ack on success (including objects
+// dropped by a stage), Nack with requeue on processing failures (the broker
+// redelivers), Nack without requeue on decode failures (redelivering cannot
+// help) and on quarantined poison messages.
+func AckingPipeline[T core.TypeCarrier](ctx context.Context, source <-chan Delivery, registry *TypeRegistry[T], options PipelineOptions, sink Sink[T], stages ...Stage[T]) *PipelineRun[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	run := &PipelineRun[T]{cancel: cancel, stopIntake: make(chan struct{})}
+
+	run.group.Add(1)
+	go func() {
+		defer run.group.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-run.stopIntake:
+				return
+			case delivery, opened := <-source:
+				if !opened {
+					return
+				}
+				run.process(ctx, delivery, registry, options, sink, stages)
+			}
+		}
+	}()
+	return run
+}
+
+func (run *PipelineRun[T]) process(ctx context.Context, delivery Delivery, registry *TypeRegistry[T], options PipelineOptions, sink Sink[T], stages []Stage[T]) {
+	object, err := registry.Unmarshal(delivery.Payload)
+	if err != nil {
+		if options.Quarantine != nil {
+			options.Quarantine.record(delivery.Payload, err)
+		}
+		_ = delivery.Acker.Nack(false)
+		return
+	}
+	if options.Quarantine != nil {
+		options.Quarantine.forget(delivery.Payload)
+	}
+	run.decoded.Add(1)
+	keep := true
+	for _, stage := range stages {
+		if object, keep, err = stage(ctx, object); err != nil {
+			_ = delivery.Acker.Nack(true)
+			return
+		} else if !keep {
+			run.filtered.Add(1)
+			break
+		}
+	}
+	if keep {
+		if err := sink(ctx, object); err != nil {
+			_ = delivery.Acker.Nack(true)
+			return
+		}
+		run.delivered.Add(1)
+	}
+	_ = delivery.Acker.Ack()
+}
@@ -0,0 +1,80 @@
+package argo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+type recordingAcker struct {
+	acked    bool
+	nacked   bool
+	requeued bool
+}
+
+func (acker *recordingAcker) Ack() error {
+	acker.acked = true
+	return nil
+}
+
+func (acker *recordingAcker) Nack(requeue bool) error {
+	acker.nacked = true
+	acker.requeued = requeue
+	return nil
+}
+
+func TestAckingPipelineAcksSuccesses(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	acker := &recordingAcker{}
+	source := make(chan argo.Delivery, 1)
+	source <- argo.Delivery{Payload: []byte(`{"type": "something1", "text": "hello"}`), Acker: acker}
+	close(source)
+
+	run := argo.AckingPipeline(context.Background(), source, registry, argo.PipelineOptions{},
+		func(ctx context.Context, object Something) error { return nil },
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if !acker.acked || acker.nacked {
+		t.Errorf("expected an ack, got %+v", acker)
+	}
+}
+
+func TestAckingPipelineNacksWithRequeueOnSinkFailure(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	acker := &recordingAcker{}
+	source := make(chan argo.Delivery, 1)
+	source <- argo.Delivery{Payload: []byte(`{"type": "something1", "text": "hello"}`), Acker: acker}
+	close(source)
+
+	run := argo.AckingPipeline(context.Background(), source, registry, argo.PipelineOptions{},
+		func(ctx context.Context, object Something) error { return errors.NotImplemented },
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if !acker.nacked || !acker.requeued {
+		t.Errorf("expected a nack with requeue, got %+v", acker)
+	}
+}
+
+func TestAckingPipelineNacksWithoutRequeueOnDecodeFailure(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	acker := &recordingAcker{}
+	source := make(chan argo.Delivery, 1)
+	source <- argo.Delivery{Payload: []byte(`{"type": "poison"}`), Acker: acker}
+	close(source)
+
+	run := argo.AckingPipeline(context.Background(), source, registry, argo.PipelineOptions{},
+		func(ctx context.Context, object Something) error { return nil },
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if !acker.nacked || acker.requeued {
+		t.Errorf("expected a nack without requeue, got %+v", acker)
+	}
+}
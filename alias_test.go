@@ -0,0 +1,57 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestAliasResolvesToCanonicalType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias("msg", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "msg", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestAliasCannotShadowCanonicalName(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	if err := registry.AddAlias("something2", "something1"); err == nil {
+		t.Error("should have failed to add alias")
+	}
+}
+
+func TestAliasMustTargetRegisteredType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	err := registry.AddAlias("msg", "something42")
+	if err == nil {
+		t.Fatal("should have failed to add alias")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestAliasesAreReportedSeparatelyInErrors(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias("msg", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	_, err := registry.Unmarshal([]byte(`{"type": "bogus"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !strings.Contains(err.Error(), "aliases: [msg]") {
+		t.Errorf("error should report aliases separately, got %s", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package argotest exports test helpers for custom registry implementations.
+//
+// Third-party registries (generated dispatchers, Redis-backed registries,
+// ...) can run the conformance suite to verify they honor the same dispatch
+// and error semantics as the built-in registries.
+package argotest
+
+import (
+	"testing"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Unmarshaler is the registry contract the conformance suite verifies.
+//
+// Implementations under test are wrapped into this interface by the factory
+// given to RunRegistryConformance.
+type Unmarshaler interface {
+	// Unmarshal unmarshals the given payload into a registered type
+	Unmarshal(payload []byte) (core.TypeCarrier, error)
+	// Size gives the number of types registered
+	Size() int
+}
+
+// First is a fixture type registered by the conformance suite
+type First struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// GetType returns the type name of the object
+func (first First) GetType() string {
+	return "first"
+}
+
+// Second is a fixture type registered by the conformance suite
+type Second struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+// GetType returns the type name of the object
+func (second Second) GetType() string {
+	return "second"
+}
+
+// RunRegistryConformance verifies a registry implementation honors the
+// dispatch and error semantics of the built-in registries.
+//
+// The factory must return a registry with a "type" tag on which the given
+// prototypes are registered.
+func RunRegistryConformance(t *testing.T, factory func(classes ...core.TypeCarrier) Unmarshaler) {
+	t.Helper()
+
+	t.Run("Size", func(t *testing.T) {
+		registry := factory(First{}, Second{})
+		if size := registry.Size(); size != 2 {
+			t.Errorf("expected 2 types, got %d", size)
+		}
+	})
+	t.Run("DispatchesOnDiscriminator", func(t *testing.T) {
+		registry := factory(First{}, Second{})
+		object, err := registry.Unmarshal([]byte(`{"type": "first", "text": "hello"}`))
+		if err != nil {
+			t.Fatalf("failed to unmarshal: %s", err)
+		}
+		first, ok := object.(*First)
+		if !ok {
+			t.Fatalf("expected a *First, got %T", object)
+		}
+		if first.Text != "hello" {
+			t.Errorf(`expected "hello", got %q`, first.Text)
+		}
+	})
+	t.Run("DecodesEachRegisteredType", func(t *testing.T) {
+		registry := factory(First{}, Second{})
+		object, err := registry.Unmarshal([]byte(`{"type": "second", "value": 7}`))
+		if err != nil {
+			t.Fatalf("failed to unmarshal: %s", err)
+		}
+		if second, ok := object.(*Second); !ok || second.Value != 7 {
+			t.Errorf("expected a *Second with value 7, got %#v", object)
+		}
+	})
+	t.Run("FailsWithoutDiscriminator", func(t *testing.T) {
+		registry := factory(First{})
+		_, err := registry.Unmarshal([]byte(`{"text": "hello"}`))
+		if err == nil {
+			t.Fatal("should have failed to unmarshal")
+		}
+		if !errors.Is(err, errors.JSONUnmarshalError) {
+			t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+		}
+		if !errors.Is(err, errors.ArgumentMissing) {
+			t.Errorf("error should be an ArgumentMissing, got %s", err)
+		}
+	})
+	t.Run("FailsOnUnknownDiscriminator", func(t *testing.T) {
+		registry := factory(First{})
+		_, err := registry.Unmarshal([]byte(`{"type": "bogus"}`))
+		if err == nil {
+			t.Fatal("should have failed to unmarshal")
+		}
+		if !errors.Is(err, errors.InvalidType) {
+			t.Errorf("error should be an InvalidType, got %s", err)
+		}
+	})
+	t.Run("FailsOnInvalidDocument", func(t *testing.T) {
+		registry := factory(First{})
+		if _, err := registry.Unmarshal([]byte(`{`)); err == nil {
+			t.Fatal("should have failed to unmarshal")
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package argotest_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/argotest"
+	"github.com/gildas/argo/core"
+)
+
+func TestTypeRegistryConformance(t *testing.T) {
+	argotest.RunRegistryConformance(t, func(classes ...core.TypeCarrier) argotest.Unmarshaler {
+		return argo.NewTypeRegistry[core.TypeCarrier]().Add(classes...)
+	})
+}
+
+func TestSyncTypeRegistryConformance(t *testing.T) {
+	argotest.RunRegistryConformance(t, func(classes ...core.TypeCarrier) argotest.Unmarshaler {
+		return argo.NewSyncTypeRegistry[core.TypeCarrier]().Add(classes...)
+	})
+}
@@ -0,0 +1,55 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestUnmarshalAssertedAcceptsMatchingClaims(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	object, err := registry.UnmarshalAsserted("something1", []byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestUnmarshalAssertedRejectsFlippedDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	_, err := registry.UnmarshalAsserted("something1", []byte(`{"type": "something2", "value": 3}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.TypeMismatch) {
+		t.Errorf("error should be a TypeMismatch, got %s", err)
+	}
+}
+
+func TestUnmarshalAssertedResolvesAliases(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias("v1.something", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	if _, err := registry.UnmarshalAsserted("v1.something", []byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestUnmarshalAssertedRequiresADiscriminator(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	_, err := registry.UnmarshalAsserted("something1", []byte(`{"text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
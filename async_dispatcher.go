@@ -0,0 +1,173 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// AsyncDispatcher decodes payloads and runs a handler on a pool of workers.
+//
+// By default payloads are spread round-robin. With a partition key, payloads
+// carrying the same key value always land on the same worker, so events for
+// one entity (a conversation, an order, ...) are processed serially while
+// different entities run in parallel.
+type AsyncDispatcher[T core.TypeCarrier] struct {
+	registry  *TypeRegistry[T]
+	handler   func(context.Context, T) error
+	onError   func(payload []byte, err error)
+	workers   int
+	keyPath   string
+	queues    []chan []byte
+	next      atomic.Int64
+	cancel    context.CancelFunc
+	group     sync.WaitGroup
+	intake    sync.RWMutex
+	closed    bool
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewAsyncDispatcher creates a dispatcher running the handler on the given
+// number of workers
+func NewAsyncDispatcher[T core.TypeCarrier](registry *TypeRegistry[T], workers int, handler func(context.Context, T) error) *AsyncDispatcher[T] {
+	if workers < 1 {
+		workers = 1
+	}
+	return &AsyncDispatcher[T]{
+		registry: registry,
+		handler:  handler,
+		workers:  workers,
+	}
+}
+
+// WithPartitionKey keys worker assignment by the given field path (dot
+// notation or JSON pointer, like type tags), e.g. "conversationId"
+func (dispatcher *AsyncDispatcher[T]) WithPartitionKey(path string) *AsyncDispatcher[T] {
+	dispatcher.keyPath = path
+	return dispatcher
+}
+
+// WithErrorHandler receives the payloads that failed to decode or whose
+// handler failed. Without it, failures are only counted.
+func (dispatcher *AsyncDispatcher[T]) WithErrorHandler(handler func(payload []byte, err error)) *AsyncDispatcher[T] {
+	dispatcher.onError = handler
+	return dispatcher
+}
+
+// Start spawns the workers. Dispatch can be called once Start returned.
+func (dispatcher *AsyncDispatcher[T]) Start(ctx context.Context) {
+	ctx, dispatcher.cancel = context.WithCancel(ctx)
+	dispatcher.queues = make([]chan []byte, dispatcher.workers)
+	for index := range dispatcher.queues {
+		queue := make(chan []byte, pipelineBuffer)
+		dispatcher.queues[index] = queue
+		dispatcher.group.Add(1)
+		go dispatcher.work(ctx, queue)
+	}
+}
+
+// Dispatch routes the payload to a worker.
+//
+// It blocks while the chosen worker's queue is full and fails once the
+// dispatcher is closing or the context is canceled.
+func (dispatcher *AsyncDispatcher[T]) Dispatch(ctx context.Context, payload []byte) error {
+	dispatcher.intake.RLock() // held across the send so Close cannot close the queue under us
+	defer dispatcher.intake.RUnlock()
+	if dispatcher.closed {
+		return errors.ArgumentInvalid.With("dispatcher", "closed")
+	}
+	queue := dispatcher.queues[dispatcher.route(payload)]
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case queue <- payload:
+		return nil
+	}
+}
+
+// Close stops intake, drains the queues within the given context's deadline,
+// then stops hard, counting whatever was left as dropped
+func (dispatcher *AsyncDispatcher[T]) Close(ctx context.Context) error {
+	dispatcher.intake.Lock() // waits for in-flight Dispatch calls before the queues close
+	if !dispatcher.closed {
+		dispatcher.closed = true
+		for _, queue := range dispatcher.queues {
+			close(queue)
+		}
+	}
+	dispatcher.intake.Unlock()
+	done := make(chan struct{})
+	go func() {
+		dispatcher.group.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		dispatcher.cancel()
+		return nil
+	case <-ctx.Done():
+		dispatcher.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats reports what happened to the payloads seen so far
+func (dispatcher *AsyncDispatcher[T]) Stats() PipelineStats {
+	return PipelineStats{
+		Delivered: dispatcher.delivered.Load(),
+		Filtered:  dispatcher.failed.Load(),
+		Dropped:   dispatcher.dropped.Load(),
+	}
+}
+
+// route picks the worker for the given payload
+func (dispatcher *AsyncDispatcher[T]) route(payload []byte) int {
+	if len(dispatcher.keyPath) > 0 {
+		var guts map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &guts); err == nil {
+			if raw, found := findTag(guts, dispatcher.keyPath); found {
+				hash := fnv.New32a()
+				_, _ = hash.Write(raw)
+				return int(hash.Sum32() % uint32(dispatcher.workers))
+			}
+		}
+	}
+	return int(dispatcher.next.Add(1) % int64(dispatcher.workers))
+}
+
+func (dispatcher *AsyncDispatcher[T]) work(ctx context.Context, queue <-chan []byte) {
+	defer dispatcher.group.Done()
+	for payload := range queue {
+		select {
+		case <-ctx.Done(): // hard shutdown: drain the queue, counting drops
+			dispatcher.dropped.Add(1)
+			continue
+		default:
+		}
+		object, err := dispatcher.registry.Unmarshal(payload)
+		if err != nil {
+			dispatcher.fail(payload, err)
+			continue
+		}
+		if err := dispatcher.handler(ctx, object); err != nil {
+			dispatcher.fail(payload, err)
+			continue
+		}
+		dispatcher.delivered.Add(1)
+	}
+}
+
+func (dispatcher *AsyncDispatcher[T]) fail(payload []byte, err error) {
+	dispatcher.failed.Add(1)
+	if dispatcher.onError != nil {
+		dispatcher.onError(payload, err)
+	}
+}
@@ -0,0 +1,123 @@
+package argo_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+type Event struct {
+	Type           string `json:"type"`
+	ConversationID string `json:"conversationId"`
+	Sequence       int    `json:"sequence"`
+}
+
+func (event Event) GetType() string { return "event" }
+
+func TestAsyncDispatcherProcessesEverything(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Event{})
+	var lock sync.Mutex
+	var count int
+	dispatcher := argo.NewAsyncDispatcher(registry, 4, func(ctx context.Context, object Something) error {
+		lock.Lock()
+		defer lock.Unlock()
+		count++
+		return nil
+	})
+	dispatcher.Start(context.Background())
+	for i := 0; i < 20; i++ {
+		payload := []byte(fmt.Sprintf(`{"type": "event", "conversationId": "c%d", "sequence": %d}`, i%5, i))
+		if err := dispatcher.Dispatch(context.Background(), payload); err != nil {
+			t.Fatalf("failed to dispatch: %s", err)
+		}
+	}
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if count != 20 {
+		t.Errorf("expected 20 processed events, got %d", count)
+	}
+}
+
+func TestAsyncDispatcherKeepsPartitionsSerial(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Event{})
+	var lock sync.Mutex
+	sequences := map[string][]int{}
+	dispatcher := argo.NewAsyncDispatcher(registry, 4, func(ctx context.Context, object Something) error {
+		event := object.(*Event)
+		lock.Lock()
+		defer lock.Unlock()
+		sequences[event.ConversationID] = append(sequences[event.ConversationID], event.Sequence)
+		return nil
+	}).WithPartitionKey("conversationId")
+	dispatcher.Start(context.Background())
+	for sequence := 0; sequence < 10; sequence++ {
+		for _, conversation := range []string{"a", "b", "c"} {
+			payload := []byte(fmt.Sprintf(`{"type": "event", "conversationId": %q, "sequence": %d}`, conversation, sequence))
+			if err := dispatcher.Dispatch(context.Background(), payload); err != nil {
+				t.Fatalf("failed to dispatch: %s", err)
+			}
+		}
+	}
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	for conversation, seen := range sequences {
+		for index, sequence := range seen {
+			if index != sequence {
+				t.Fatalf("conversation %s processed out of order: %v", conversation, seen)
+			}
+		}
+	}
+}
+
+func TestAsyncDispatcherReportsFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Event{})
+	var lock sync.Mutex
+	var failures int
+	dispatcher := argo.NewAsyncDispatcher(registry, 2, func(ctx context.Context, object Something) error {
+		return nil
+	}).WithErrorHandler(func(payload []byte, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		failures++
+	})
+	dispatcher.Start(context.Background())
+	if err := dispatcher.Dispatch(context.Background(), []byte(`{"type": "bogus"}`)); err != nil {
+		t.Fatalf("failed to dispatch: %s", err)
+	}
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	if failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failures)
+	}
+}
+
+func TestAsyncDispatcherSurvivesCloseUnderLoad(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Event{})
+	dispatcher := argo.NewAsyncDispatcher(registry, 4, func(ctx context.Context, object Something) error {
+		return nil
+	})
+	dispatcher.Start(context.Background())
+	var group sync.WaitGroup
+	for producer := 0; producer < 4; producer++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			payload := []byte(`{"type": "event", "conversationId": "c", "sequence": 1}`)
+			for dispatcher.Dispatch(context.Background(), payload) == nil {
+			}
+		}()
+	}
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	group.Wait()
+	if err := dispatcher.Dispatch(context.Background(), []byte(`{"type": "event"}`)); err == nil {
+		t.Error("dispatching on a closed dispatcher should fail")
+	}
+}
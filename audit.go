@@ -0,0 +1,43 @@
+package argo
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// AuditEntry records one registration change on a Sync registry
+type AuditEntry struct {
+	// Operation is what happened, "add" or "remove"
+	Operation string `json:"operation"`
+	// Type is the type name the operation was about
+	Type string `json:"type"`
+	// Caller is the file:line that performed the operation
+	Caller string `json:"caller"`
+	// Time is when the operation happened
+	Time time.Time `json:"time"`
+}
+
+// auditCaller gives the file:line of the code that called into the registry,
+// skipping the given number of frames above the registry method
+func auditCaller(skip int) string {
+	if _, file, line, ok := runtime.Caller(skip + 1); ok {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return "unknown"
+}
+
+// auditEntries builds one entry per type name for the given operation
+func auditEntries(operation string, typenames []string, caller string) []AuditEntry {
+	entries := make([]AuditEntry, 0, len(typenames))
+	now := time.Now()
+	for _, typename := range typenames {
+		entries = append(entries, AuditEntry{
+			Operation: operation,
+			Type:      typename,
+			Caller:    caller,
+			Time:      now,
+		})
+	}
+	return entries
+}
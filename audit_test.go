@@ -0,0 +1,74 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestAuditTrailRecordsAddAndRemove(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]().WithAuditTrail()
+	registry.Add(Something1{}, Something2{})
+	registry.Remove("something2")
+
+	log := registry.AuditLog()
+	if len(log) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(log))
+	}
+	if log[0].Operation != "add" || log[0].Type != "something1" {
+		t.Errorf("unexpected first entry: %+v", log[0])
+	}
+	if log[2].Operation != "remove" || log[2].Type != "something2" {
+		t.Errorf("unexpected last entry: %+v", log[2])
+	}
+	for _, entry := range log {
+		if !strings.Contains(entry.Caller, "audit_test.go") {
+			t.Errorf("entry should point at this test, got %q", entry.Caller)
+		}
+		if entry.Time.IsZero() {
+			t.Error("entry should carry a timestamp")
+		}
+	}
+	if registry.Size() != 1 {
+		t.Errorf("expected 1 registered type, got %d", registry.Size())
+	}
+}
+
+func TestAuditTrailIsOptIn(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]()
+	registry.Add(Something1{})
+
+	if log := registry.AuditLog(); len(log) != 0 {
+		t.Errorf("expected no entries, got %d", len(log))
+	}
+}
+
+func TestAuditTrailOnPropertyRegistries(t *testing.T) {
+	registry := argo.NewSyncPropertyRegistry[Something]().WithAuditTrail()
+	registry.Add(Something1{})
+	registry.Remove("something1")
+
+	log := registry.AuditLog()
+	if len(log) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(log))
+	}
+	if registry.Size() != 0 {
+		t.Errorf("expected 0 registered types, got %d", registry.Size())
+	}
+}
+
+func TestRemoveDropsAliases(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias("first", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+	registry.Remove("something1")
+
+	if registry.Size() != 0 {
+		t.Errorf("expected 0 registered types, got %d", registry.Size())
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "first"}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
@@ -0,0 +1,65 @@
+package argo
+
+import "encoding/json"
+
+// JSONBackend bundles the JSON entry points the registries call on their hot
+// path, so a faster drop-in (go-json, jsoniter, sonic) can stand in for
+// encoding/json without this module importing it:
+//
+//	registry.WithJSONBackend(argo.JSONBackend{
+//		Marshal:   gojson.Marshal,
+//		Unmarshal: gojson.Unmarshal,
+//	})
+//
+// Deployments preferring build tags over runtime wiring can keep that call
+// in a file of its own guarded by e.g. //go:build gojson, next to a default
+// file wiring nothing.
+//
+// The backend drives the discriminator pass and the struct decode; options
+// its signatures cannot express (Strict, UseNumber) and the context-chunked
+// decode of large payloads keep using encoding/json.
+type JSONBackend struct {
+	Marshal   func(value any) ([]byte, error)
+	Unmarshal func(payload []byte, value any) error
+}
+
+// WithJSONBackend makes the registry parse and serialize JSON through the
+// given backend
+func (registry *TypeRegistry[T]) WithJSONBackend(backend JSONBackend) *TypeRegistry[T] {
+	registry.backend = backend
+	return registry
+}
+
+// WithJSONBackend makes the registry parse JSON through the given backend
+// (see TypeRegistry.WithJSONBackend)
+func (registry *PropertyRegistry[T]) WithJSONBackend(backend JSONBackend) *PropertyRegistry[T] {
+	registry.backend = backend
+	return registry
+}
+
+// jsonUnmarshal unmarshals through the configured backend, falling back to
+// encoding/json
+func (registry *TypeRegistry[T]) jsonUnmarshal(payload []byte, value any) error {
+	if registry.backend.Unmarshal != nil {
+		return registry.backend.Unmarshal(payload, value)
+	}
+	return json.Unmarshal(payload, value)
+}
+
+// jsonMarshal marshals through the configured backend, falling back to
+// encoding/json
+func (registry *TypeRegistry[T]) jsonMarshal(value any) ([]byte, error) {
+	if registry.backend.Marshal != nil {
+		return registry.backend.Marshal(value)
+	}
+	return json.Marshal(value)
+}
+
+// jsonUnmarshal unmarshals through the configured backend, falling back to
+// encoding/json
+func (registry *PropertyRegistry[T]) jsonUnmarshal(payload []byte, value any) error {
+	if registry.backend.Unmarshal != nil {
+		return registry.backend.Unmarshal(payload, value)
+	}
+	return json.Unmarshal(payload, value)
+}
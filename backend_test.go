@@ -0,0 +1,91 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestCanUnmarshalThroughJSONBackend(t *testing.T) {
+	calls := 0
+	backend := argo.JSONBackend{
+		Unmarshal: func(payload []byte, value any) error {
+			calls++
+			return json.Unmarshal(payload, value)
+		},
+	}
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithJSONBackend(backend)
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if calls < 2 { // discriminator pass + struct decode
+		t.Errorf("expected the backend to handle both passes, got %d call(s)", calls)
+	}
+}
+
+func TestCanMarshalThroughJSONBackend(t *testing.T) {
+	calls := 0
+	backend := argo.JSONBackend{
+		Marshal: func(value any) ([]byte, error) {
+			calls++
+			return json.Marshal(value)
+		},
+	}
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithJSONBackend(backend)
+
+	data, err := registry.ToMap(&Something1{Type: "something1", Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["text"] != "hello" {
+		t.Errorf(`expected "hello", got %v`, data["text"])
+	}
+	if calls != 1 {
+		t.Errorf("expected the backend to marshal, got %d call(s)", calls)
+	}
+}
+
+func TestBackendShouldYieldToStrictDecoding(t *testing.T) {
+	backend := argo.JSONBackend{
+		Unmarshal: func(payload []byte, value any) error {
+			return json.Unmarshal(payload, value) // would accept unknown fields
+		},
+	}
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).Strict().WithJSONBackend(backend)
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "bogus": true}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestCanUnmarshalPropertyThroughJSONBackend(t *testing.T) {
+	calls := 0
+	backend := argo.JSONBackend{
+		Unmarshal: func(payload []byte, value any) error {
+			calls++
+			return json.Unmarshal(payload, value)
+		},
+	}
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}).WithJSONBackend(backend)
+
+	object, err := registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if calls < 2 {
+		t.Errorf("expected the backend to handle both passes, got %d call(s)", calls)
+	}
+}
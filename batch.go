@@ -0,0 +1,75 @@
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gildas/argo/errors"
+)
+
+// BatchFailure is one failed element of a batch decode
+type BatchFailure struct {
+	// Index is the position of the element in the batch
+	Index int
+	// Discriminator is the type the element claimed, when it carried one
+	Discriminator string
+	// Cause is why the element failed to decode
+	Cause error
+}
+
+// BatchError aggregates the failures of a batch decode.
+//
+// It unwraps to every cause, so errors.Is and errors.As see through it
+// (e.g. errors.Is(err, argo.ErrUnknownType) reports whether any element
+// carried an unknown discriminator).
+type BatchError struct {
+	Failures []BatchFailure
+}
+
+// Error satisfies the error interface
+func (batch *BatchError) Error() string {
+	first := batch.Failures[0]
+	if len(batch.Failures) == 1 {
+		return fmt.Sprintf("element %d (type %q) failed: %s", first.Index, first.Discriminator, first.Cause)
+	}
+	return fmt.Sprintf("%d elements failed, first: element %d (type %q): %s", len(batch.Failures), first.Index, first.Discriminator, first.Cause)
+}
+
+// Unwrap gives the cause of every failure
+func (batch *BatchError) Unwrap() []error {
+	causes := make([]error, 0, len(batch.Failures))
+	for _, failure := range batch.Failures {
+		causes = append(causes, failure.Cause)
+	}
+	return causes
+}
+
+// UnmarshalSlice unmarshals a JSON array of polymorphic objects.
+//
+// Every element carries its own discriminator. Elements that decode
+// successfully are always returned; when some fail, the error is a
+// *BatchError recording the index, claimed discriminator and cause of each
+// failure, so a consumer can process the good elements and report or
+// dead-letter the bad ones.
+func (registry *TypeRegistry[T]) UnmarshalSlice(payload []byte) ([]T, error) {
+	var elements []json.RawMessage
+
+	if err := registry.jsonUnmarshal(payload, &elements); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+	}
+	objects := make([]T, 0, len(elements))
+	batch := &BatchError{}
+	for index, raw := range elements {
+		object, err := registry.Unmarshal(raw)
+		if err != nil {
+			discriminator, _, _ := scanDiscriminator(raw, registry.tags)
+			batch.Failures = append(batch.Failures, BatchFailure{Index: index, Discriminator: discriminator, Cause: err})
+			continue
+		}
+		objects = append(objects, object)
+	}
+	if len(batch.Failures) > 0 {
+		return objects, batch
+	}
+	return objects, nil
+}
@@ -0,0 +1,55 @@
+package argo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestCanUnmarshalSlices(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+
+	objects, err := registry.UnmarshalSlice([]byte(`[
+		{"type": "something1", "text": "hello"},
+		{"type": "something2", "value": 7}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if _, ok := objects[0].(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", objects[0])
+	}
+}
+
+func TestUnmarshalSliceAggregatesFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	objects, err := registry.UnmarshalSlice([]byte(`[
+		{"type": "something1", "text": "hello"},
+		{"type": "bogus"},
+		{"type": "something1", "text": 42}
+	]`))
+	if len(objects) != 1 {
+		t.Fatalf("expected 1 decoded object, got %d", len(objects))
+	}
+	var batch *argo.BatchError
+	if !errors.As(err, &batch) {
+		t.Fatalf("error should be a BatchError, got %T: %v", err, err)
+	}
+	if len(batch.Failures) != 2 {
+		t.Fatalf("expected 2 failures, got %d", len(batch.Failures))
+	}
+	if batch.Failures[0].Index != 1 || batch.Failures[0].Discriminator != "bogus" {
+		t.Errorf("unexpected first failure: %+v", batch.Failures[0])
+	}
+	if batch.Failures[1].Index != 2 {
+		t.Errorf("unexpected second failure: %+v", batch.Failures[1])
+	}
+	if !errors.Is(err, argo.ErrUnknownType) {
+		t.Errorf("error should match ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package argo
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// ChainRegistry tries several registries in order on Unmarshal.
+//
+// A core registry can thus be combined with plugin-provided ones: the first
+// registry that recognizes the payload decodes it. A registry that does not
+// know the payload (errors.InvalidType, errors.ArgumentMissing) passes the
+// turn; any other failure is reported as is, since that registry claimed
+// the payload. When no registry matches, the error aggregates the type
+// names known across the whole chain.
+type ChainRegistry[T core.TypeCarrier] struct {
+	registries []Registry[T]
+}
+
+// NewChainRegistry creates a new ChainRegistry over the given registries,
+// tried in order
+func NewChainRegistry[T core.TypeCarrier](registries ...Registry[T]) *ChainRegistry[T] {
+	return &ChainRegistry[T]{registries: registries}
+}
+
+// Unmarshal unmarshals the given payload through the first registry in the
+// chain that recognizes it
+func (registry *ChainRegistry[T]) Unmarshal(payload []byte) (object T, err error) {
+	for _, next := range registry.registries {
+		object, err = next.Unmarshal(payload)
+		if err == nil {
+			return object, nil
+		}
+		if errors.Is(err, errors.InvalidType) || errors.Is(err, errors.ArgumentMissing) {
+			continue
+		}
+		return object, err
+	}
+	return object, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With("payload", fmt.Sprintf("%v", registry.Types())))
+}
+
+// Remove removes a type from every registry in the chain
+func (registry *ChainRegistry[T]) Remove(typename string) {
+	for _, next := range registry.registries {
+		next.Remove(typename)
+	}
+}
+
+// Size gives the number of types registered across the chain; a type
+// registered on several registries counts once per registry
+func (registry *ChainRegistry[T]) Size() int {
+	size := 0
+	for _, next := range registry.registries {
+		size += next.Size()
+	}
+	return size
+}
+
+// Types gives the sorted, deduplicated type names known across the chain
+func (registry *ChainRegistry[T]) Types() []string {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, next := range registry.registries {
+		for _, typename := range next.Types() {
+			if !seen[typename] {
+				seen[typename] = true
+				types = append(types, typename)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+var _ Registry[core.TypeCarrier] = (*ChainRegistry[core.TypeCarrier])(nil)
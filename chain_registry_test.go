@@ -0,0 +1,56 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestChainRegistryTriesRegistriesInOrder(t *testing.T) {
+	coreRegistry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	pluginRegistry := argo.NewTypeRegistry[Something]("type").Add(Something2{})
+	registry := argo.NewChainRegistry[Something](coreRegistry, pluginRegistry)
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something2); !ok {
+		t.Fatalf("expected a *Something2, got %T", object)
+	}
+	if size := registry.Size(); size != 2 {
+		t.Errorf("expected 2 types, got %d", size)
+	}
+}
+
+func TestChainRegistryAggregatesKnownTypes(t *testing.T) {
+	registry := argo.NewChainRegistry[Something](
+		argo.NewTypeRegistry[Something]("type").Add(Something1{}),
+		argo.NewTypeRegistry[Something]("type").Add(Something2{}),
+	)
+
+	_, err := registry.Unmarshal([]byte(`{"type": "bogus"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Fatalf("error should be an InvalidType, got %s", err)
+	}
+	if !strings.Contains(err.Error(), "something1") || !strings.Contains(err.Error(), "something2") {
+		t.Errorf("error should list the types of the whole chain, got %s", err)
+	}
+}
+
+func TestChainRegistryReportsClaimedFailures(t *testing.T) {
+	strictRegistry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).Strict()
+	fallback := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	registry := argo.NewChainRegistry[Something](strictRegistry, fallback)
+
+	// the first registry knows the type and fails decoding it; the chain
+	// must not paper over that by falling through
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "bogus": true}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
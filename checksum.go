@@ -0,0 +1,97 @@
+package argo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/gildas/argo/errors"
+)
+
+// ChecksumPolicy verifies a checksum declared inside the payload before the
+// payload is decoded.
+//
+// Pipelines whose payloads pass through untrusted hops declare a checksum
+// field next to the data, e.g. {"sha256": "...", "payload": {...}}. The
+// policy recomputes it and rejects tampered payloads with an
+// errors.IntegrityError before any decoding work happens.
+type ChecksumPolicy struct {
+	// Field is the JSON key carrying the hex SHA-256 checksum (e.g. "sha256")
+	Field string
+	// Target is the key of the sub-document the checksum covers. When empty,
+	// the checksum covers the whole payload in canonical form: the checksum
+	// field removed, values compacted, keys sorted.
+	Target string
+}
+
+// VerifyChecksum makes Unmarshal verify the given checksum policy before
+// decoding
+func (registry *TypeRegistry[T]) VerifyChecksum(policy ChecksumPolicy) *TypeRegistry[T] {
+	registry.checksum = &policy
+	return registry
+}
+
+// verify checks the payload against the policy
+func (policy ChecksumPolicy) verify(guts map[string]json.RawMessage) error {
+	raw, found := guts[policy.Field]
+	if !found {
+		return errors.ArgumentMissing.With(policy.Field)
+	}
+	var declared string
+	if err := json.Unmarshal(raw, &declared); err != nil {
+		return errors.ArgumentInvalid.With(policy.Field, string(raw))
+	}
+	covered, err := policy.covered(guts)
+	if err != nil {
+		return err
+	}
+	computed := sha256.Sum256(covered)
+	if hex.EncodeToString(computed[:]) != declared {
+		return errors.IntegrityError.With(policy.Field)
+	}
+	return nil
+}
+
+// covered gives the bytes the checksum is computed over
+func (policy ChecksumPolicy) covered(guts map[string]json.RawMessage) ([]byte, error) {
+	if len(policy.Target) > 0 {
+		raw, found := guts[policy.Target]
+		if !found {
+			return nil, errors.ArgumentMissing.With(policy.Target)
+		}
+		return compactJSON(raw)
+	}
+	canonical := make(map[string]json.RawMessage, len(guts))
+	for key, raw := range guts {
+		if key == policy.Field {
+			continue
+		}
+		compacted, err := compactJSON(raw)
+		if err != nil {
+			return nil, err
+		}
+		canonical[key] = compacted
+	}
+	return json.Marshal(canonical) // map keys are marshaled in sorted order
+}
+
+// compactJSON removes insignificant whitespace from a raw document
+func compactJSON(raw json.RawMessage) (json.RawMessage, error) {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, raw); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return compacted.Bytes(), nil
+}
+
+// Checksum computes the hex SHA-256 declared value for the given
+// sub-document, for producers writing checksummed payloads
+func Checksum(payload []byte) (string, error) {
+	compacted, err := compactJSON(payload)
+	if err != nil {
+		return "", err
+	}
+	computed := sha256.Sum256(compacted)
+	return hex.EncodeToString(computed[:]), nil
+}
@@ -0,0 +1,65 @@
+package argo_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestChecksumAcceptsValidPayloads(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		VerifyChecksum(argo.ChecksumPolicy{Field: "sha256", Target: "data"})
+
+	data := `{"note": "hello"}`
+	sum, err := argo.Checksum([]byte(data))
+	if err != nil {
+		t.Fatalf("failed to compute checksum: %s", err)
+	}
+	payload := fmt.Sprintf(`{"type": "something1", "sha256": %q, "data": %s}`, sum, data)
+	if _, err := registry.Unmarshal([]byte(payload)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestChecksumRejectsTamperedPayloads(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		VerifyChecksum(argo.ChecksumPolicy{Field: "sha256", Target: "data"})
+
+	sum, _ := argo.Checksum([]byte(`{"note": "hello"}`))
+	payload := fmt.Sprintf(`{"type": "something1", "sha256": %q, "data": {"note": "tampered"}}`, sum)
+	_, err := registry.Unmarshal([]byte(payload))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.IntegrityError) {
+		t.Errorf("error should be an IntegrityError, got %s", err)
+	}
+}
+
+func TestChecksumRequiresItsField(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		VerifyChecksum(argo.ChecksumPolicy{Field: "sha256"})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestChecksumCoversWholePayloadWithoutTarget(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		VerifyChecksum(argo.ChecksumPolicy{Field: "sha256"})
+
+	// canonical form: checksum field removed, keys sorted, values compacted
+	canonical := `{"text":"hello","type":"something1"}`
+	sum, _ := argo.Checksum([]byte(canonical))
+	payload := fmt.Sprintf(`{"type": "something1", "sha256": %q, "text": "hello"}`, sum)
+	if _, err := registry.Unmarshal([]byte(payload)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
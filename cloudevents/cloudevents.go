@@ -0,0 +1,121 @@
+// Package cloudevents unmarshals CloudEvents through an argo registry.
+//
+// CloudEvents carry their discriminator in the "type" context attribute and
+// their business payload in "data" (or "data_base64" for binary payloads).
+// This adapter dispatches on the event type through a TypeRegistry, in both
+// HTTP content modes:
+//
+//   - structured mode: the whole event is a JSON document, in the body
+//   - binary mode: the context attributes travel as ce-* headers and the
+//     body is the data
+//
+// making argo directly usable in Knative and other event-driven services.
+package cloudevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Event carries the context attributes of a CloudEvent, i.e. everything
+// around the decoded data
+type Event struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject,omitempty"`
+	DataContentType string `json:"datacontenttype,omitempty"`
+	Time            string `json:"time,omitempty"`
+}
+
+// envelope is a structured-mode CloudEvent document
+type envelope struct {
+	Event
+	Data       json.RawMessage `json:"data"`
+	DataBase64 string          `json:"data_base64"`
+}
+
+// Unmarshal unmarshals a structured-mode CloudEvent.
+//
+// The event data is dispatched on the "type" context attribute through the
+// given registry; binary payloads carried in "data_base64" are decoded
+// first. The context attributes are given alongside the decoded object.
+func Unmarshal[T core.TypeCarrier](registry *argo.TypeRegistry[T], payload []byte) (object T, event *Event, err error) {
+	var document envelope
+
+	if err = json.Unmarshal(payload, &document); err != nil {
+		return object, nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if document.Type == "" {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("type"))
+	}
+	data := []byte(document.Data)
+	if len(data) == 0 && document.DataBase64 != "" {
+		if data, err = base64.StdEncoding.DecodeString(document.DataBase64); err != nil {
+			return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("data_base64", err.Error()))
+		}
+	}
+	if len(data) == 0 {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("data"))
+	}
+	object, err = decode(registry, document.Type, data)
+	return object, &document.Event, err
+}
+
+// FromRequest unmarshals the CloudEvent carried by an HTTP request, in
+// either content mode.
+//
+// Requests with a Content-Type of application/cloudevents+json are decoded
+// in structured mode; any other request is treated as binary mode, with the
+// context attributes read from the ce-* headers and the body as the data.
+func FromRequest[T core.TypeCarrier](registry *argo.TypeRegistry[T], r *http.Request) (object T, event *Event, err error) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return object, nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if contentType := r.Header.Get("Content-Type"); strings.HasPrefix(contentType, "application/cloudevents+json") {
+		return Unmarshal(registry, payload)
+	}
+	event = &Event{
+		SpecVersion:     r.Header.Get("ce-specversion"),
+		ID:              r.Header.Get("ce-id"),
+		Source:          r.Header.Get("ce-source"),
+		Type:            r.Header.Get("ce-type"),
+		Subject:         r.Header.Get("ce-subject"),
+		Time:            r.Header.Get("ce-time"),
+		DataContentType: r.Header.Get("Content-Type"),
+	}
+	if event.Type == "" {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("ce-type"))
+	}
+	object, err = decode(registry, event.Type, payload)
+	return object, event, err
+}
+
+// decode dispatches the event data on the given type through the registry,
+// by merging the discriminator into the data document
+func decode[T core.TypeCarrier](registry *argo.TypeRegistry[T], typename string, data []byte) (object T, err error) {
+	guts := map[string]json.RawMessage{}
+	if err = json.Unmarshal(data, &guts); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	tags := registry.Tags()
+	if len(tags) == 0 {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("tags"))
+	}
+	discriminator, _ := json.Marshal(typename)
+	guts[tags[0]] = discriminator
+	merged, err := json.Marshal(guts)
+	if err != nil {
+		return object, errors.JSONMarshalError.Wrap(err)
+	}
+	return registry.Unmarshal(merged)
+}
@@ -0,0 +1,128 @@
+package cloudevents_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/cloudevents"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+type Message interface {
+	core.TypeCarrier
+}
+
+type Greeting struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (greeting Greeting) GetType() string {
+	return "com.example.greeting"
+}
+
+func TestCloudEventsDecodesStructuredMode(t *testing.T) {
+	registry := argo.NewTypeRegistry[Message]().Add(Greeting{})
+
+	payload := `{
+		"specversion": "1.0",
+		"id": "1234",
+		"source": "/example",
+		"type": "com.example.greeting",
+		"datacontenttype": "application/json",
+		"data": {"text": "hello"}
+	}`
+	object, event, err := cloudevents.Unmarshal(registry, []byte(payload))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	greeting, ok := object.(*Greeting)
+	if !ok {
+		t.Fatalf("expected a *Greeting, got %T", object)
+	}
+	if greeting.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, greeting.Text)
+	}
+	if event.ID != "1234" || event.Source != "/example" {
+		t.Errorf("unexpected event attributes: %+v", event)
+	}
+}
+
+func TestCloudEventsDecodesBase64Data(t *testing.T) {
+	registry := argo.NewTypeRegistry[Message]().Add(Greeting{})
+
+	payload := `{
+		"specversion": "1.0",
+		"id": "1234",
+		"source": "/example",
+		"type": "com.example.greeting",
+		"data_base64": "eyJ0ZXh0IjogImhlbGxvIn0="
+	}`
+	object, _, err := cloudevents.Unmarshal(registry, []byte(payload))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if greeting := object.(*Greeting); greeting.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, greeting.Text)
+	}
+}
+
+func TestCloudEventsDecodesBinaryMode(t *testing.T) {
+	registry := argo.NewTypeRegistry[Message]().Add(Greeting{})
+
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(`{"text": "hello"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("ce-specversion", "1.0")
+	r.Header.Set("ce-id", "1234")
+	r.Header.Set("ce-source", "/example")
+	r.Header.Set("ce-type", "com.example.greeting")
+
+	object, event, err := cloudevents.FromRequest(registry, r)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if greeting := object.(*Greeting); greeting.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, greeting.Text)
+	}
+	if event.ID != "1234" || event.Type != "com.example.greeting" {
+		t.Errorf("unexpected event attributes: %+v", event)
+	}
+}
+
+func TestCloudEventsDecodesStructuredRequests(t *testing.T) {
+	registry := argo.NewTypeRegistry[Message]().Add(Greeting{})
+
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(`{
+		"specversion": "1.0",
+		"id": "1234",
+		"source": "/example",
+		"type": "com.example.greeting",
+		"data": {"text": "hello"}
+	}`))
+	r.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+
+	object, _, err := cloudevents.FromRequest(registry, r)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Greeting); !ok {
+		t.Errorf("expected a *Greeting, got %T", object)
+	}
+}
+
+func TestCloudEventsShouldFailWithoutType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Message]().Add(Greeting{})
+
+	if _, _, err := cloudevents.Unmarshal(registry, []byte(`{"specversion": "1.0", "data": {}}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+
+	r := httptest.NewRequest("POST", "/events", strings.NewReader(`{"text": "hello"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if _, _, err := cloudevents.FromRequest(registry, r); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// carrier is one type implementing GetType with a constant result
+type carrier struct {
+	// TypeName is the Go type name bearing the GetType method
+	TypeName string
+	// Discriminator is the constant string GetType returns
+	Discriminator string
+}
+
+// scanPackage finds the carriers of a package.
+//
+// A type qualifies when it has a GetType() string method whose body is a
+// single return of a string literal; methods computing their type name are
+// skipped, since the generated switch needs compile-time discriminators.
+func scanPackage(dir string) (string, []carrier, error) {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", nil, err
+	}
+	name := ""
+	carriers := []carrier{}
+	for _, pkg := range packages {
+		if strings.HasSuffix(pkg.Name, "_test") {
+			continue
+		}
+		name = pkg.Name
+		for _, file := range pkg.Files {
+			for _, declaration := range file.Decls {
+				if found, ok := carrierOf(declaration); ok {
+					carriers = append(carriers, found)
+				}
+			}
+		}
+	}
+	sort.Slice(carriers, func(i, j int) bool { return carriers[i].Discriminator < carriers[j].Discriminator })
+	return name, carriers, nil
+}
+
+// carrierOf tells if a declaration is a qualifying GetType method
+func carrierOf(declaration ast.Decl) (carrier, bool) {
+	function, ok := declaration.(*ast.FuncDecl)
+	if !ok || function.Name.Name != "GetType" || function.Recv == nil || len(function.Recv.List) != 1 {
+		return carrier{}, false
+	}
+	if function.Body == nil || len(function.Body.List) != 1 {
+		return carrier{}, false
+	}
+	result, ok := function.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(result.Results) != 1 {
+		return carrier{}, false
+	}
+	literal, ok := result.Results[0].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return carrier{}, false
+	}
+	discriminator, err := strconv.Unquote(literal.Value)
+	if err != nil {
+		return carrier{}, false
+	}
+	receiver := function.Recv.List[0].Type
+	if pointer, ok := receiver.(*ast.StarExpr); ok {
+		receiver = pointer.X
+	}
+	identifier, ok := receiver.(*ast.Ident)
+	if !ok {
+		return carrier{}, false
+	}
+	return carrier{TypeName: identifier.Name, Discriminator: discriminator}, true
+}
+
+// generate renders the dispatch function and registration helper
+func generate(pkg string, carriers []carrier, funcName string, tag string) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "// Code generated by argogen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buffer, "package %s\n\n", pkg)
+	fmt.Fprintf(&buffer, "import (\n")
+	fmt.Fprintf(&buffer, "\t\"encoding/json\"\n\n")
+	fmt.Fprintf(&buffer, "\t\"github.com/gildas/argo/core\"\n")
+	fmt.Fprintf(&buffer, "\t\"github.com/gildas/argo/errors\"\n")
+	fmt.Fprintf(&buffer, ")\n\n")
+
+	fmt.Fprintf(&buffer, "// %s unmarshals a payload into the type matching its %q discriminator, without reflection\n", funcName, tag)
+	fmt.Fprintf(&buffer, "func %s(payload []byte) (core.TypeCarrier, error) {\n", funcName)
+	fmt.Fprintf(&buffer, "\tvar header struct {\n\t\tType string `json:%q`\n\t}\n", tag)
+	fmt.Fprintf(&buffer, "\tif err := json.Unmarshal(payload, &header); err != nil {\n")
+	fmt.Fprintf(&buffer, "\t\treturn nil, errors.JSONUnmarshalError.Wrap(err)\n\t}\n")
+	fmt.Fprintf(&buffer, "\tswitch header.Type {\n")
+	for _, entry := range carriers {
+		fmt.Fprintf(&buffer, "\tcase %q:\n", entry.Discriminator)
+		fmt.Fprintf(&buffer, "\t\tvar value %s\n", entry.TypeName)
+		fmt.Fprintf(&buffer, "\t\tif err := json.Unmarshal(payload, &value); err != nil {\n")
+		fmt.Fprintf(&buffer, "\t\t\treturn nil, errors.JSONUnmarshalError.Wrap(err)\n\t\t}\n")
+		fmt.Fprintf(&buffer, "\t\treturn &value, nil\n")
+	}
+	names := make([]string, 0, len(carriers))
+	for _, entry := range carriers {
+		names = append(names, entry.Discriminator)
+	}
+	fmt.Fprintf(&buffer, "\tcase \"\":\n")
+	fmt.Fprintf(&buffer, "\t\treturn nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(%q))\n", tag)
+	fmt.Fprintf(&buffer, "\tdefault:\n")
+	fmt.Fprintf(&buffer, "\t\treturn nil, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(header.Type, %#v))\n", names)
+	fmt.Fprintf(&buffer, "\t}\n}\n\n")
+
+	fmt.Fprintf(&buffer, "// %sTypes lists the scanned types, for registry registration\n", funcName)
+	fmt.Fprintf(&buffer, "func %sTypes() []core.TypeCarrier {\n", funcName)
+	fmt.Fprintf(&buffer, "\treturn []core.TypeCarrier{\n")
+	for _, entry := range carriers {
+		fmt.Fprintf(&buffer, "\t\t%s{},\n", entry.TypeName)
+	}
+	fmt.Fprintf(&buffer, "\t}\n}\n")
+
+	return format.Source(buffer.Bytes())
+}
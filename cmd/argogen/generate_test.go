@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const scannedSource = `package events
+
+type UserCreated struct {
+	Type string ` + "`json:\"type\"`" + `
+	ID   string ` + "`json:\"id\"`" + `
+}
+
+func (event UserCreated) GetType() string {
+	return "user.created"
+}
+
+type UserDeleted struct {
+	Type string ` + "`json:\"type\"`" + `
+}
+
+func (event *UserDeleted) GetType() string {
+	return "user.deleted"
+}
+
+// computed type names cannot be dispatched at compile time
+type Dynamic struct {
+	Kind string
+}
+
+func (event Dynamic) GetType() string {
+	return "dynamic." + event.Kind
+}
+`
+
+func scannedPackage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "events.go"), []byte(scannedSource), 0644); err != nil {
+		t.Fatalf("failed to write source: %s", err)
+	}
+	return dir
+}
+
+func TestScanPackageFindsConstantCarriers(t *testing.T) {
+	pkg, carriers, err := scanPackage(scannedPackage(t))
+	if err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+	if pkg != "events" {
+		t.Errorf(`expected package "events", got %q`, pkg)
+	}
+	if len(carriers) != 2 {
+		t.Fatalf("expected 2 carriers, got %d: %v", len(carriers), carriers)
+	}
+	if carriers[0].TypeName != "UserCreated" || carriers[0].Discriminator != "user.created" {
+		t.Errorf("unexpected first carrier: %+v", carriers[0])
+	}
+	if carriers[1].TypeName != "UserDeleted" || carriers[1].Discriminator != "user.deleted" {
+		t.Errorf("unexpected second carrier: %+v", carriers[1])
+	}
+}
+
+func TestGenerateRendersTheDispatch(t *testing.T) {
+	_, carriers, err := scanPackage(scannedPackage(t))
+	if err != nil {
+		t.Fatalf("failed to scan: %s", err)
+	}
+	source, err := generate("events", carriers, "UnmarshalGenerated", "type")
+	if err != nil {
+		t.Fatalf("failed to generate: %s", err)
+	}
+	code := string(source)
+	for _, expected := range []string{
+		"// Code generated by argogen. DO NOT EDIT.",
+		"package events",
+		`case "user.created":`,
+		"var value UserCreated",
+		`case "user.deleted":`,
+		"func UnmarshalGeneratedTypes() []core.TypeCarrier {",
+		"UserCreated{},",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("generated code should contain %q", expected)
+		}
+	}
+	if strings.Contains(code, "Dynamic") {
+		t.Error("generated code should not dispatch computed type names")
+	}
+}
@@ -0,0 +1,44 @@
+// Command argogen generates reflection-free unmarshal dispatch for a package.
+//
+// It scans a package for types implementing GetType() with a constant result
+// and writes a switch-based unmarshal function plus a helper listing the
+// types for registry registration, eliminating reflect.New and map lookups
+// on hot paths. It is meant to be driven by go:generate:
+//
+//	//go:generate argogen -package . -output argo_generated.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var (
+		dir      = flag.String("package", ".", "directory of the package to scan")
+		output   = flag.String("output", "argo_generated.go", "file to write the generated code to")
+		funcName = flag.String("func", "UnmarshalGenerated", "name of the generated unmarshal function")
+		tag      = flag.String("tag", "type", "JSON key carrying the discriminator")
+	)
+	flag.Parse()
+
+	pkg, carriers, err := scanPackage(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(carriers) == 0 {
+		fmt.Fprintf(os.Stderr, "no GetType implementations with a constant result found in %s\n", *dir)
+		os.Exit(1)
+	}
+	source, err := generate(pkg, carriers, *funcName, *tag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(*output, source, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
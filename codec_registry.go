@@ -0,0 +1,104 @@
+package argo
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// WithCodec makes the registry decode payloads through the given Codec
+// instead of JSON.
+//
+// The codec path supports the common registry features: tag lookup (at the
+// top level of the document), aliases, case folding, the default type, the
+// unknown-type handler, post-unmarshal hooks and validation. JSON-specific
+// options (Strict, UseNumber, checksums, schema validation, migrations and
+// nested tag paths) only apply to the built-in JSON path.
+func (registry *TypeRegistry[T]) WithCodec(codec Codec) *TypeRegistry[T] {
+	registry.codec = codec
+	return registry
+}
+
+// WithCodec makes the registry decode payloads through the given Codec
+// instead of JSON (see TypeRegistry.WithCodec)
+func (registry *PropertyRegistry[T]) WithCodec(codec Codec) *PropertyRegistry[T] {
+	registry.codec = codec
+	return registry
+}
+
+// unmarshalWithCodec is the codec-driven counterpart of UnmarshalContext
+func (registry *TypeRegistry[T]) unmarshalWithCodec(ctx context.Context, codec Codec, payload []byte) (object T, err error) {
+	if err = ctx.Err(); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	keys, err := codec.Keys(payload)
+	if err != nil {
+		return object, err
+	}
+	for _, tag := range registry.tags {
+		raw, found := keys[tag]
+		if !found {
+			continue
+		}
+		var value any
+		if err = codec.Unmarshal(raw, &value); err != nil {
+			return object, err
+		}
+		typename, err := discriminatorString(value)
+		if err != nil {
+			return object, errors.UnmarshalError.Wrap(err)
+		}
+		typename, class, found := registry.resolve(typename)
+		if !found {
+			if registry.unknownHandler != nil {
+				if registry.copyPayloads {
+					payload = append([]byte(nil), payload...)
+				}
+				object, err = registry.unknownHandler(typename, payload)
+				if err != nil {
+					return object, errors.UnmarshalError.Wrap(err)
+				}
+				if err = registry.applyUnmarshalHooks(typename, object); err != nil {
+					return object, err
+				}
+				return object, registry.validate(ctx, object)
+			}
+			if registry.defaultClass == nil {
+				return object, errors.UnmarshalError.Wrap(errors.InvalidType.With(typename, registry.supportedTypes()))
+			}
+			class = registry.defaultClass
+		}
+		target := reflect.New(class).Interface()
+		if err = codec.Unmarshal(payload, target); err != nil {
+			return object, err
+		}
+		object = asRegistered[T](target)
+		if err = registry.applyUnmarshalHooks(typename, object); err != nil {
+			return object, err
+		}
+		return object, registry.validate(ctx, object)
+	}
+	return object, errors.UnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+}
+
+// unmarshalWithCodec is the codec-driven counterpart of PropertyRegistry.Unmarshal
+func (registry *PropertyRegistry[T]) unmarshalWithCodec(payload []byte) (object T, matched string, err error) {
+	keys, err := registry.codec.Keys(payload)
+	if err != nil {
+		return object, "", err
+	}
+	for _, key := range registry.order {
+		raw, found := keys[key]
+		if !found {
+			continue
+		}
+		value := reflect.New(registry.classes[key]).Interface()
+		if err = registry.codec.Unmarshal(raw, value); err != nil {
+			return object, key, err
+		}
+		return asRegistered[T](value), key, nil
+	}
+	return object, "", errors.UnmarshalError.Wrap(errors.ArgumentMissing.With("property"))
+}
@@ -0,0 +1,77 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/codecs/msgpack"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCanUnmarshalTypeWithCodec(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{}).WithCodec(msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"type": "something1", "text": "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	object, err := registry.Unmarshal(payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestShouldFailUnmarshalUnknownTypeWithCodec(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithCodec(msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"type": "bogus"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if _, err := registry.Unmarshal(payload); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestShouldFailUnmarshalWithoutTypeWithCodec(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithCodec(msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"text": "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if _, err := registry.Unmarshal(payload); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestCanUnmarshalPropertyWithCodec(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{}).WithCodec(msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"something2": map[string]any{"value": int64(7)}})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	object, err := registry.Unmarshal(payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something2, ok := object.(*Something2)
+	if !ok {
+		t.Fatalf("expected a *Something2, got %T", object)
+	}
+	if something2.Value != 7 {
+		t.Errorf("expected 7, got %d", something2.Value)
+	}
+}
@@ -0,0 +1,137 @@
+// Package msgpack implements the argo.Codec interface for MessagePack.
+//
+// The codec is hand-written against the MessagePack specification and
+// imports nothing but the standard library, keeping argo's dependency-light
+// contract (see ARCHITECTURE.md). It covers the types a JSON-shaped
+// document uses: nil, booleans, integers, floats, strings, binary, arrays
+// and string-keyed maps; extension types are not supported.
+//
+// Structs are bridged through their JSON representation: decoding goes
+// msgpack → generic value → encoding/json → struct, so the usual json tags
+// drive the field mapping. This trades some speed for a single source of
+// truth on struct layout.
+package msgpack
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gildas/argo/errors"
+)
+
+// Codec translates between MessagePack and Go values, implementing the
+// argo.Codec interface
+type Codec struct{}
+
+// Name gives the name of the format
+func (codec Codec) Name() string {
+	return "msgpack"
+}
+
+// Keys decodes the top level of a document into raw sub-documents.
+//
+// The document must be a string-keyed map; each value is returned as its
+// raw msgpack bytes, aliasing the input payload.
+func (codec Codec) Keys(payload []byte) (map[string][]byte, error) {
+	d := &decoder{payload: payload}
+	marker, err := d.byte()
+	if err != nil {
+		return nil, errors.UnmarshalError.Wrap(err)
+	}
+	var size int
+	switch {
+	case marker >= 0x80 && marker <= 0x8f:
+		size = int(marker & 0x0f)
+	case marker == 0xde:
+		if size, err = d.length(2); err != nil {
+			return nil, errors.UnmarshalError.Wrap(err)
+		}
+	case marker == 0xdf:
+		if size, err = d.length(4); err != nil {
+			return nil, errors.UnmarshalError.Wrap(err)
+		}
+	default:
+		return nil, errors.UnmarshalError.Wrap(errors.ArgumentInvalid.With("document", "not a map"))
+	}
+	keys := make(map[string][]byte, size)
+	for index := 0; index < size; index++ {
+		key, err := d.value()
+		if err != nil {
+			return nil, errors.UnmarshalError.Wrap(err)
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, errors.UnmarshalError.Wrap(errors.ArgumentInvalid.With("map key", key))
+		}
+		start := d.offset
+		if _, err = d.value(); err != nil {
+			return nil, errors.UnmarshalError.Wrap(err)
+		}
+		keys[name] = payload[start:d.offset]
+	}
+	return keys, nil
+}
+
+// Unmarshal decodes a document into the given value.
+//
+// The document is decoded generically, then bridged into the value through
+// encoding/json, honoring its json tags.
+func (codec Codec) Unmarshal(payload []byte, value any) error {
+	d := &decoder{payload: payload}
+	decoded, err := d.value()
+	if err != nil {
+		return errors.UnmarshalError.Wrap(err)
+	}
+	bridge, err := json.Marshal(decoded)
+	if err != nil {
+		return errors.UnmarshalError.Wrap(err)
+	}
+	if err := json.Unmarshal(bridge, value); err != nil {
+		return errors.UnmarshalError.Wrap(err)
+	}
+	return nil
+}
+
+// Marshal encodes the given value into a document, bridging structs through
+// their JSON representation
+func (codec Codec) Marshal(value any) ([]byte, error) {
+	bridge, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.MarshalError.Wrap(err)
+	}
+	jsonDecoder := json.NewDecoder(bytes.NewReader(bridge))
+	jsonDecoder.UseNumber()
+	var generic any
+	if err := jsonDecoder.Decode(&generic); err != nil {
+		return nil, errors.MarshalError.Wrap(err)
+	}
+	e := &encoder{}
+	if err := e.value(normalize(generic)); err != nil {
+		return nil, errors.MarshalError.Wrap(err)
+	}
+	return e.buffer.Bytes(), nil
+}
+
+// normalize turns json.Number values into int64 or float64 for the encoder
+func normalize(value any) any {
+	switch actual := value.(type) {
+	case json.Number:
+		if integer, err := actual.Int64(); err == nil {
+			return integer
+		}
+		number, _ := actual.Float64()
+		return number
+	case []any:
+		for index, element := range actual {
+			actual[index] = normalize(element)
+		}
+		return actual
+	case map[string]any:
+		for key, element := range actual {
+			actual[key] = normalize(element)
+		}
+		return actual
+	default:
+		return value
+	}
+}
@@ -0,0 +1,118 @@
+package msgpack_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gildas/argo/codecs/msgpack"
+)
+
+func TestCodecRoundTripsDocuments(t *testing.T) {
+	codec := msgpack.Codec{}
+
+	document := map[string]any{
+		"type":   "something1",
+		"count":  int64(42),
+		"ratio":  2.5,
+		"active": true,
+		"tags":   []any{"a", "b"},
+		"nested": map[string]any{"key": "value"},
+	}
+	payload, err := codec.Marshal(document)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	var decoded map[string]any
+	if err := codec.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if decoded["type"] != "something1" {
+		t.Errorf(`expected "something1", got %v`, decoded["type"])
+	}
+	if decoded["count"] != float64(42) {
+		t.Errorf("expected 42, got %v (%T)", decoded["count"], decoded["count"])
+	}
+	if decoded["ratio"] != 2.5 {
+		t.Errorf("expected 2.5, got %v", decoded["ratio"])
+	}
+	if !reflect.DeepEqual(decoded["tags"], []any{"a", "b"}) {
+		t.Errorf("unexpected tags: %v", decoded["tags"])
+	}
+}
+
+func TestCodecDecodesIntoStructs(t *testing.T) {
+	codec := msgpack.Codec{}
+
+	type event struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+	payload, err := codec.Marshal(event{Type: "user.created", ID: "U1"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+
+	var decoded event
+	if err := codec.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if decoded.ID != "U1" {
+		t.Errorf(`expected "U1", got %q`, decoded.ID)
+	}
+}
+
+func TestCodecExposesTopLevelKeys(t *testing.T) {
+	codec := msgpack.Codec{}
+
+	payload, err := codec.Marshal(map[string]any{"type": "something1", "value": int64(3)})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	keys, err := codec.Keys(payload)
+	if err != nil {
+		t.Fatalf("failed to read keys: %s", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	var discriminator string
+	if err := codec.Unmarshal(keys["type"], &discriminator); err != nil {
+		t.Fatalf("failed to unmarshal key: %s", err)
+	}
+	if discriminator != "something1" {
+		t.Errorf(`expected "something1", got %q`, discriminator)
+	}
+}
+
+func TestCodecRejectsNonMapDocumentsInKeys(t *testing.T) {
+	codec := msgpack.Codec{}
+
+	payload, err := codec.Marshal([]any{"a"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if _, err := codec.Keys(payload); err == nil {
+		t.Error("should have failed to read keys")
+	}
+}
+
+func TestCodecName(t *testing.T) {
+	if name := (msgpack.Codec{}).Name(); name != "msgpack" {
+		t.Errorf(`expected "msgpack", got %q`, name)
+	}
+}
+
+func TestCodecRejectsHostileDeclaredLengths(t *testing.T) {
+	codec := msgpack.Codec{}
+	payloads := map[string][]byte{
+		"array32": {0xdd, 0xff, 0xff, 0xff, 0xff},
+		"map32":   {0xdf, 0xff, 0xff, 0xff, 0xff},
+	}
+	for name, payload := range payloads {
+		var decoded any
+		if err := codec.Unmarshal(payload, &decoded); err == nil {
+			t.Errorf("a truncated %s should fail to decode", name)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/gildas/argo/errors"
+)
+
+// decoder reads msgpack values from a byte slice, tracking its offset so
+// Keys can slice the raw bytes of each value
+type decoder struct {
+	payload []byte
+	offset  int
+}
+
+// value decodes the next msgpack value into its generic Go form: nil, bool,
+// int64, uint64, float64, string, []byte, []any or map[string]any
+func (d *decoder) value() (any, error) {
+	marker, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case marker <= 0x7f: // positive fixint
+		return int64(marker), nil
+	case marker >= 0xe0: // negative fixint
+		return int64(int8(marker)), nil
+	case marker >= 0x80 && marker <= 0x8f: // fixmap
+		return d.mapValue(int(marker & 0x0f))
+	case marker >= 0x90 && marker <= 0x9f: // fixarray
+		return d.arrayValue(int(marker & 0x0f))
+	case marker >= 0xa0 && marker <= 0xbf: // fixstr
+		return d.stringValue(int(marker & 0x1f))
+	}
+	switch marker {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6: // bin8/16/32
+		length, err := d.length(1 << (marker - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.bytes(length)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), data...), nil
+	case 0xca: // float32
+		data, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+	case 0xcb: // float64
+		data, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint8/16/32/64
+		length, err := d.length(1 << (marker - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return uint64(length), nil
+	case 0xd0: // int8
+		value, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		return int64(int8(value)), nil
+	case 0xd1: // int16
+		data, err := d.bytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int16(binary.BigEndian.Uint16(data))), nil
+	case 0xd2: // int32
+		data, err := d.bytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return int64(int32(binary.BigEndian.Uint32(data))), nil
+	case 0xd3: // int64
+		data, err := d.bytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.BigEndian.Uint64(data)), nil
+	case 0xd9, 0xda, 0xdb: // str8/16/32
+		length, err := d.length(1 << (marker - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.stringValue(length)
+	case 0xdc, 0xdd: // array16/32
+		length, err := d.length(2 << (marker - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.arrayValue(length)
+	case 0xde, 0xdf: // map16/32
+		length, err := d.length(2 << (marker - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapValue(length)
+	}
+	return nil, errors.ArgumentInvalid.With("marker", marker)
+}
+
+// length reads a big-endian unsigned length of the given width in bytes
+func (d *decoder) length(width int) (int, error) {
+	data, err := d.bytes(width)
+	if err != nil {
+		return 0, err
+	}
+	length := uint64(0)
+	for _, octet := range data {
+		length = length<<8 | uint64(octet)
+	}
+	return int(length), nil
+}
+
+// stringValue reads a string of the given length
+func (d *decoder) stringValue(length int) (string, error) {
+	data, err := d.bytes(length)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// arrayValue reads the given number of values
+func (d *decoder) arrayValue(length int) ([]any, error) {
+	// the declared count comes from the wire; cap the preallocation by the
+	// bytes left (one per element at least) so a hostile header cannot force
+	// a huge allocation, and let append grow from there
+	elements := make([]any, 0, min(length, len(d.payload)-d.offset))
+	for index := 0; index < length; index++ {
+		element, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
+
+// mapValue reads the given number of key/value pairs; keys must be strings
+func (d *decoder) mapValue(length int) (map[string]any, error) {
+	// see arrayValue; a map entry needs at least two bytes on the wire
+	object := make(map[string]any, min(length, (len(d.payload)-d.offset)/2))
+	for index := 0; index < length; index++ {
+		key, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(string)
+		if !ok {
+			return nil, errors.ArgumentInvalid.With("map key", key)
+		}
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+	return object, nil
+}
+
+// byte reads one byte
+func (d *decoder) byte() (byte, error) {
+	if d.offset >= len(d.payload) {
+		return 0, errors.ArgumentMissing.With("data")
+	}
+	value := d.payload[d.offset]
+	d.offset++
+	return value, nil
+}
+
+// bytes reads the given number of bytes
+func (d *decoder) bytes(length int) ([]byte, error) {
+	if d.offset+length > len(d.payload) {
+		return nil, errors.ArgumentMissing.With("data")
+	}
+	data := d.payload[d.offset : d.offset+length]
+	d.offset += length
+	return data, nil
+}
@@ -0,0 +1,125 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/gildas/argo/errors"
+)
+
+// encoder writes msgpack values
+type encoder struct {
+	buffer bytes.Buffer
+}
+
+// value encodes one generic Go value: nil, bool, int64, uint64, float64,
+// string, []byte, []any or map[string]any
+func (e *encoder) value(value any) error {
+	switch actual := value.(type) {
+	case nil:
+		e.buffer.WriteByte(0xc0)
+	case bool:
+		if actual {
+			e.buffer.WriteByte(0xc3)
+		} else {
+			e.buffer.WriteByte(0xc2)
+		}
+	case int64:
+		e.integer(actual)
+	case uint64:
+		if actual > math.MaxInt64 {
+			e.buffer.WriteByte(0xcf)
+			e.lengthBytes(actual, 8)
+		} else {
+			e.integer(int64(actual))
+		}
+	case float64:
+		e.buffer.WriteByte(0xcb)
+		e.lengthBytes(math.Float64bits(actual), 8)
+	case string:
+		e.stringValue(actual)
+	case []byte:
+		e.header(len(actual), 0, 0xc4, 0xc5, 0xc6)
+		e.buffer.Write(actual)
+	case []any:
+		e.header(len(actual), 0x90, 0, 0xdc, 0xdd)
+		for _, element := range actual {
+			if err := e.value(element); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(actual))
+		for key := range actual {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		e.header(len(actual), 0x80, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			e.stringValue(key)
+			if err := e.value(actual[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.ArgumentInvalid.With("value", value)
+	}
+	return nil
+}
+
+// integer encodes an int64 in its smallest form
+func (e *encoder) integer(value int64) {
+	switch {
+	case value >= 0 && value <= 0x7f:
+		e.buffer.WriteByte(byte(value))
+	case value < 0 && value >= -32:
+		e.buffer.WriteByte(byte(value))
+	default:
+		e.buffer.WriteByte(0xd3)
+		e.lengthBytes(uint64(value), 8)
+	}
+}
+
+// stringValue encodes a string with the smallest header
+func (e *encoder) stringValue(value string) {
+	if len(value) <= 31 {
+		e.buffer.WriteByte(0xa0 | byte(len(value)))
+	} else if len(value) <= math.MaxUint8 {
+		e.buffer.WriteByte(0xd9)
+		e.buffer.WriteByte(byte(len(value)))
+	} else if len(value) <= math.MaxUint16 {
+		e.buffer.WriteByte(0xda)
+		e.lengthBytes(uint64(len(value)), 2)
+	} else {
+		e.buffer.WriteByte(0xdb)
+		e.lengthBytes(uint64(len(value)), 4)
+	}
+	e.buffer.WriteString(value)
+}
+
+// header encodes a container header: fix form when available, else the
+// 8/16-bit and 32-bit forms
+func (e *encoder) header(length int, fix byte, narrow byte, medium byte, wide byte) {
+	switch {
+	case fix > 0 && length <= 0x0f:
+		e.buffer.WriteByte(fix | byte(length))
+	case narrow > 0 && length <= math.MaxUint8:
+		e.buffer.WriteByte(narrow)
+		e.buffer.WriteByte(byte(length))
+	case length <= math.MaxUint16:
+		e.buffer.WriteByte(medium)
+		e.lengthBytes(uint64(length), 2)
+	default:
+		e.buffer.WriteByte(wide)
+		e.lengthBytes(uint64(length), 4)
+	}
+}
+
+// lengthBytes writes a big-endian unsigned value of the given width
+func (e *encoder) lengthBytes(value uint64, width int) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, value)
+	e.buffer.Write(data[8-width:])
+}
@@ -0,0 +1,79 @@
+package argo
+
+import (
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// CompositeRegistry handles both wire shapes some producer fleets mix for
+// the same logical types:
+//
+//	{"type": "something1", "text": "hello"}
+//	{"something1": {"text": "hello"}}
+//
+// Each Add registers the type for discriminator dispatch and property
+// dispatch at once. Unmarshal tries type-tag resolution first and falls
+// back to property resolution; PropertyFirst flips that order.
+type CompositeRegistry[T core.TypeCarrier] struct {
+	types         *TypeRegistry[T]
+	properties    *PropertyRegistry[T]
+	propertyFirst bool
+}
+
+// NewCompositeRegistry creates a new CompositeRegistry with the given type
+// tags (by default "type")
+func NewCompositeRegistry[T core.TypeCarrier](tags ...string) *CompositeRegistry[T] {
+	return &CompositeRegistry[T]{
+		types:      NewTypeRegistry[T](tags...),
+		properties: NewPropertyRegistry[T](),
+	}
+}
+
+// PropertyFirst makes the registry attempt property resolution before
+// type-tag resolution
+func (registry *CompositeRegistry[T]) PropertyFirst() *CompositeRegistry[T] {
+	registry.propertyFirst = true
+	return registry
+}
+
+// Add adds one or more types to the registry, for both dispatch strategies
+func (registry *CompositeRegistry[T]) Add(classes ...T) *CompositeRegistry[T] {
+	registry.types.Add(classes...)
+	registry.properties.Add(classes...)
+	return registry
+}
+
+// Remove removes a type from both dispatch strategies
+func (registry *CompositeRegistry[T]) Remove(typename string) {
+	registry.types.Remove(typename)
+	registry.properties.Remove(typename)
+}
+
+// Size gives the number of types registered
+func (registry *CompositeRegistry[T]) Size() int {
+	return registry.types.Size()
+}
+
+// Types gives the registered type names, in registration order
+func (registry *CompositeRegistry[T]) Types() []string {
+	return registry.types.Types()
+}
+
+// Unmarshal unmarshals the given payload, resolving its type through the
+// configured strategy order
+func (registry *CompositeRegistry[T]) Unmarshal(payload []byte) (object T, err error) {
+	first, second := Registry[T](registry.types), Registry[T](registry.properties)
+	if registry.propertyFirst {
+		first, second = second, first
+	}
+	object, err = first.Unmarshal(payload)
+	if err == nil {
+		return object, nil
+	}
+	if errors.Is(err, errors.InvalidType) || errors.Is(err, errors.ArgumentMissing) {
+		return second.Unmarshal(payload)
+	}
+	return object, err
+}
+
+var _ Registry[core.TypeCarrier] = (*CompositeRegistry[core.TypeCarrier])(nil)
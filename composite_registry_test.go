@@ -0,0 +1,54 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCompositeRegistryHandlesBothWireShapes(t *testing.T) {
+	registry := argo.NewCompositeRegistry[Something]("type").Add(Something1{}, Something2{})
+
+	for _, payload := range []string{
+		`{"type": "something1", "text": "hello"}`,
+		`{"something1": {"text": "hello"}}`,
+	} {
+		object, err := registry.Unmarshal([]byte(payload))
+		if err != nil {
+			t.Fatalf("failed to unmarshal %s: %s", payload, err)
+		}
+		something1, ok := object.(*Something1)
+		if !ok {
+			t.Fatalf("expected a *Something1, got %T", object)
+		}
+		if something1.Text != "hello" {
+			t.Errorf(`expected "hello", got %q`, something1.Text)
+		}
+	}
+}
+
+func TestCompositeRegistryCanResolvePropertiesFirst(t *testing.T) {
+	registry := argo.NewCompositeRegistry[Something]("type").PropertyFirst().Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if _, err = registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestCompositeRegistryShouldFailUnknownPayloads(t *testing.T) {
+	registry := argo.NewCompositeRegistry[Something]("type").Add(Something1{})
+
+	if _, err := registry.Unmarshal([]byte(`{"bogus": {}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
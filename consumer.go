@@ -0,0 +1,88 @@
+package argo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// DecodeFunc gives the registry's Unmarshal as a standalone function, the
+// shape most broker client libraries accept for their message handlers
+func (registry *TypeRegistry[T]) DecodeFunc() func([]byte) (T, error) {
+	return registry.Unmarshal
+}
+
+// Consumer adapts a Dispatcher to broker consumer loops (Kafka, NATS,
+// RabbitMQ, ...) without argo importing any broker library.
+//
+// Messages are decoded and routed to the dispatcher's typed handlers, under
+// a concurrency limit; messages that fail to decode or whose handler fails
+// go to the dead-letter callback:
+//
+//	consumer := argo.NewConsumer(dispatcher, 8).
+//	    WithDeadLetter(func(payload []byte, err error) { ... })
+//	// from the broker's delivery callback:
+//	consumer.Go(ctx, message.Body)
+type Consumer[T core.TypeCarrier] struct {
+	dispatcher *Dispatcher[T]
+	limit      chan struct{}
+	deadLetter func(payload []byte, err error)
+	group      sync.WaitGroup
+}
+
+// NewConsumer creates a new Consumer routing through the given dispatcher,
+// handling at most concurrency messages at once (default: 1)
+func NewConsumer[T core.TypeCarrier](dispatcher *Dispatcher[T], concurrency int) *Consumer[T] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Consumer[T]{
+		dispatcher: dispatcher,
+		limit:      make(chan struct{}, concurrency),
+	}
+}
+
+// WithDeadLetter makes the consumer hand failed messages to the given
+// callback, along with the failure
+func (consumer *Consumer[T]) WithDeadLetter(handler func(payload []byte, err error)) *Consumer[T] {
+	consumer.deadLetter = handler
+	return consumer
+}
+
+// Consume decodes and dispatches one message, blocking while the
+// concurrency limit is reached.
+//
+// The failure, if any, is given to the dead-letter callback and returned,
+// so broker loops can also nack or requeue.
+func (consumer *Consumer[T]) Consume(ctx context.Context, payload []byte) error {
+	select {
+	case consumer.limit <- struct{}{}:
+	case <-ctx.Done():
+		return errors.JSONUnmarshalError.Wrap(ctx.Err())
+	}
+	defer func() { <-consumer.limit }()
+	if err := consumer.dispatcher.Dispatch(ctx, payload); err != nil {
+		if consumer.deadLetter != nil {
+			consumer.deadLetter(payload, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// Go consumes one message in its own goroutine, for broker clients that
+// deliver from a single loop; failures only reach the dead-letter callback
+func (consumer *Consumer[T]) Go(ctx context.Context, payload []byte) {
+	consumer.group.Add(1)
+	go func() {
+		defer consumer.group.Done()
+		_ = consumer.Consume(ctx, payload)
+	}()
+}
+
+// Wait blocks until every message handed to Go has been handled
+func (consumer *Consumer[T]) Wait() {
+	consumer.group.Wait()
+}
@@ -0,0 +1,71 @@
+package argo_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestDecodeFuncFeedsConsumerLoops(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	decode := registry.DecodeFunc()
+	object, err := decode([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestConsumerDispatchesMessages(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	dispatcher := argo.NewDispatcher(registry)
+
+	var handled atomic.Int32
+	argo.Handle(dispatcher, func(ctx context.Context, object *Something1) error {
+		handled.Add(1)
+		return nil
+	})
+
+	consumer := argo.NewConsumer(dispatcher, 4)
+	for i := 0; i < 10; i++ {
+		consumer.Go(context.Background(), []byte(fmt.Sprintf(`{"type": "something1", "text": "message %d"}`, i)))
+	}
+	consumer.Wait()
+	if handled.Load() != 10 {
+		t.Errorf("expected 10 handled messages, got %d", handled.Load())
+	}
+}
+
+func TestConsumerDeadLettersFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	dispatcher := argo.NewDispatcher(registry)
+	argo.Handle(dispatcher, func(ctx context.Context, object *Something1) error { return nil })
+
+	var lock sync.Mutex
+	letters := [][]byte{}
+	consumer := argo.NewConsumer(dispatcher, 2).WithDeadLetter(func(payload []byte, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if !errors.Is(err, errors.InvalidType) {
+			t.Errorf("error should be an InvalidType, got %s", err)
+		}
+		letters = append(letters, payload)
+	})
+
+	if err := consumer.Consume(context.Background(), []byte(`{"type": "bogus"}`)); err == nil {
+		t.Error("should have failed to consume")
+	}
+	consumer.Go(context.Background(), []byte(`{"type": "bogus"}`))
+	consumer.Wait()
+	if len(letters) != 2 {
+		t.Errorf("expected 2 dead letters, got %d", len(letters))
+	}
+}
@@ -0,0 +1,50 @@
+package argo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// deadlineCheckChunk is how many bytes are decoded between two context
+// checks when a decode is time-boxed
+const deadlineCheckChunk = 64 * 1024
+
+// contextReader checks its context before every read, so a canceled or
+// expired context interrupts a streaming decode between chunks
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read satisfies the io.Reader interface
+func (reader *contextReader) Read(p []byte) (int, error) {
+	if err := reader.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(p) > deadlineCheckChunk {
+		p = p[:deadlineCheckChunk]
+	}
+	return reader.reader.Read(p)
+}
+
+// decodeContext unmarshals like decode, enforcing the context's deadline on
+// large payloads by checking it every deadlineCheckChunk bytes.
+//
+// Small payloads and background contexts decode directly; the chunked path
+// returns the context's error (context.DeadlineExceeded or context.Canceled)
+// as the decode failure, which callers wrap in an errors.JSONUnmarshalError.
+func (registry *TypeRegistry[T]) decodeContext(ctx context.Context, payload []byte, value any) error {
+	if ctx.Done() == nil || len(payload) <= deadlineCheckChunk {
+		return registry.decode(payload, value)
+	}
+	decoder := json.NewDecoder(&contextReader{ctx: ctx, reader: bytes.NewReader(payload)})
+	if registry.strict {
+		decoder.DisallowUnknownFields()
+	}
+	if registry.useNumber {
+		decoder.UseNumber()
+	}
+	return decoder.Decode(value)
+}
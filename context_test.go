@@ -0,0 +1,100 @@
+package argo_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestUnmarshalContextReadsTheRegistry(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	object, err := registry.UnmarshalContext(context.Background(), []byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestUnmarshalContextHonorsCancellation(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := registry.UnmarshalContext(ctx, []byte(`{"type": "something1", "text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error should wrap context.Canceled, got %s", err)
+	}
+	if !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+}
+
+func TestUnmarshalContextHonorsDeadlines(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, err := registry.UnmarshalContext(ctx, []byte(`{"type": "something1", "text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error should wrap context.DeadlineExceeded, got %s", err)
+	}
+}
+
+// cancelingReader cancels its context after the first read, so the next
+// chunk of a streaming decode sees a canceled context
+type cancelingReader struct {
+	reader io.Reader
+	cancel context.CancelFunc
+}
+
+func (reader *cancelingReader) Read(p []byte) (n int, err error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	n, err = reader.reader.Read(p)
+	reader.cancel()
+	return n, err
+}
+
+func TestUnmarshalFromContextStopsMidStream(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := &cancelingReader{reader: strings.NewReader(`{"type": "something1", "text": "hello"}`), cancel: cancel}
+	_, err := registry.UnmarshalFromContext(ctx, source)
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error should wrap context.Canceled, got %s", err)
+	}
+}
+
+func TestUnmarshalFromContextHonorsCancellation(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := registry.UnmarshalFromContext(ctx, strings.NewReader(`{"type": "something1"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error should wrap context.Canceled, got %s", err)
+	}
+}
@@ -0,0 +1,10 @@
+package core
+
+// TypeCarrier describes objects that carry their own type name.
+//
+// The type name is the discriminator used by the registries to
+// match a JSON payload with the Go type it should be decoded into.
+type TypeCarrier interface {
+	// GetType returns the type name of the object
+	GetType() string
+}
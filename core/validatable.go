@@ -0,0 +1,19 @@
+package core
+
+import "context"
+
+// Validatable describes objects that can check their own invariants.
+//
+// Registries configured with ValidateObjects call Validate after decoding
+// and fail the decode when it returns an error.
+type Validatable interface {
+	// Validate tells if the object is valid
+	Validate() error
+}
+
+// ValidatableContext describes objects whose validation needs a context,
+// e.g. to look up reference data with a deadline.
+type ValidatableContext interface {
+	// Validate tells if the object is valid
+	Validate(ctx context.Context) error
+}
@@ -0,0 +1,106 @@
+package argo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// describeDecodeError augments encoding/json decode failures with where
+// they happened: the byte offset and the JSON path of the offending value,
+// e.g. ".data.items[3].id". Other errors pass through untouched.
+func describeDecodeError(payload []byte, err error) error {
+	var typeError *json.UnmarshalTypeError
+	var syntaxError *json.SyntaxError
+
+	switch {
+	case errors.As(err, &typeError):
+		return fmt.Errorf("%w at offset %d (path %s)", err, typeError.Offset, jsonPathAt(payload, typeError.Offset))
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("%w at offset %d (path %s)", err, syntaxError.Offset, jsonPathAt(payload, syntaxError.Offset))
+	}
+	return err
+}
+
+// jsonPathAt gives the JSON path of the value enclosing the given byte
+// offset, by walking the payload token by token
+func jsonPathAt(payload []byte, offset int64) string {
+	type frame struct {
+		object  bool   // container is an object, not an array
+		key     string // key of the value being parsed, for objects
+		index   int    // index of the value being parsed, for arrays
+		keyNext bool   // the next object token is a key
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	stack := []frame{}
+
+	render := func() string {
+		path := strings.Builder{}
+		for _, level := range stack {
+			if level.object {
+				if len(level.key) > 0 {
+					path.WriteString("." + level.key)
+				}
+			} else if level.index >= 0 {
+				fmt.Fprintf(&path, "[%d]", level.index)
+			}
+		}
+		if path.Len() == 0 {
+			return "$"
+		}
+		return path.String()
+	}
+
+	for {
+		if decoder.InputOffset() >= offset {
+			return render()
+		}
+		token, err := decoder.Token()
+		if err != nil {
+			return render()
+		}
+		top := func() *frame {
+			if len(stack) == 0 {
+				return nil
+			}
+			return &stack[len(stack)-1]
+		}
+		completeValue := func() {
+			if current := top(); current != nil {
+				if current.object {
+					current.keyNext = true
+				} else {
+					current.index++
+				}
+			}
+		}
+		switch actual := token.(type) {
+		case json.Delim:
+			switch actual {
+			case '{', '[':
+				if parent := top(); parent != nil && !parent.object {
+					parent.index++
+				}
+				stack = append(stack, frame{object: actual == '{', index: -1, keyNext: true})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if parent := top(); parent != nil && parent.object {
+					parent.keyNext = true
+				}
+			}
+		case string:
+			if current := top(); current != nil && current.object && current.keyNext {
+				current.key = actual
+				current.keyNext = false
+				continue
+			}
+			completeValue()
+		default:
+			completeValue()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+// Nested exercises decode failures deep inside a payload
+type Nested struct {
+	Type string `json:"type"`
+	Data struct {
+		Items []struct {
+			ID string `json:"id"`
+		} `json:"items"`
+	} `json:"data"`
+}
+
+func (nested Nested) GetType() string {
+	return "nested"
+}
+
+func TestDecodeErrorsCarryOffsetAndPath(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Nested{})
+
+	payload := []byte(`{"type": "nested", "data": {"items": [{"id": "a"}, {"id": "b"}, {"id": 42}]}}`)
+	_, err := registry.Unmarshal(payload)
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !strings.Contains(err.Error(), "offset") {
+		t.Errorf("error should carry the byte offset, got %s", err)
+	}
+	if !strings.Contains(err.Error(), ".data.items[2].id") {
+		t.Errorf("error should carry the JSON path, got %s", err)
+	}
+}
+
+func TestSyntaxErrorsCarryOffset(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something1", "text": }`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !strings.Contains(err.Error(), "offset") {
+		t.Errorf("error should carry the byte offset, got %s", err)
+	}
+}
@@ -0,0 +1,54 @@
+package argo
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Decoder reads and unmarshals registered objects from a stream.
+//
+// A Decoder is bound to a TypeRegistry and dispatches every document it reads
+// on the registry's discriminator, so callers can consume a stream of
+// heterogeneous documents without buffering it:
+//
+//	decoder := registry.NewDecoder(reader)
+//	for decoder.More() {
+//	    object, err := decoder.Decode()
+//	    ...
+//	}
+type Decoder[T core.TypeCarrier] struct {
+	registry *TypeRegistry[T]
+	decoder  *json.Decoder
+}
+
+// NewDecoder gives a Decoder reading from the given reader.
+//
+// The reader may carry a single document, a stream of whitespace-separated
+// documents, or JSON-Lines; json.Decoder handles all three.
+func (registry *TypeRegistry[T]) NewDecoder(reader io.Reader) *Decoder[T] {
+	return &Decoder[T]{
+		registry: registry,
+		decoder:  json.NewDecoder(reader),
+	}
+}
+
+// More tells if the stream carries another document
+func (decoder *Decoder[T]) More() bool {
+	return decoder.decoder.More()
+}
+
+// Decode reads the next document from the stream and unmarshals it.
+//
+// At the end of the stream, Decode fails with an errors.JSONUnmarshalError
+// wrapping io.EOF, so callers can test for it with errors.Is.
+func (decoder *Decoder[T]) Decode() (object T, err error) {
+	var raw json.RawMessage
+
+	if err = decoder.decoder.Decode(&raw); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return decoder.registry.Unmarshal(raw)
+}
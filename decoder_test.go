@@ -0,0 +1,61 @@
+package argo_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestDecoderReadsAStream(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	stream := `{"type": "something1", "text": "hello"}
+{"type": "something2", "value": 3}`
+	decoder := registry.NewDecoder(strings.NewReader(stream))
+
+	objects := []Something{}
+	for decoder.More() {
+		object, err := decoder.Decode()
+		if err != nil {
+			t.Fatalf("failed to decode: %s", err)
+		}
+		objects = append(objects, object)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if something1 := objects[0].(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if something2 := objects[1].(*Something2); something2.Value != 3 {
+		t.Errorf("expected 3, got %d", something2.Value)
+	}
+}
+
+func TestDecoderFailsWithEOFAtTheEnd(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	decoder := registry.NewDecoder(strings.NewReader(""))
+	if decoder.More() {
+		t.Error("stream should not carry another document")
+	}
+	_, err := decoder.Decode()
+	if err == nil {
+		t.Fatal("should have failed to decode")
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("error should wrap io.EOF, got %s", err)
+	}
+}
+
+func TestDecoderFailsOnUnknownTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	decoder := registry.NewDecoder(strings.NewReader(`{"type": "whatever"}`))
+	if _, err := decoder.Decode(); err == nil {
+		t.Error("should have failed to decode")
+	}
+}
@@ -0,0 +1,83 @@
+package argo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Dispatcher decodes payloads through a registry and routes the decoded
+// objects to handlers registered per concrete type.
+//
+// This removes the type-switch boilerplate of websocket and queue
+// consumers:
+//
+//	dispatcher := argo.NewDispatcher(registry)
+//	argo.Handle(dispatcher, func(ctx context.Context, event *Something1) error { ... })
+//	err := dispatcher.Dispatch(ctx, payload)
+type Dispatcher[T core.TypeCarrier] struct {
+	registry *TypeRegistry[T]
+	handlers map[reflect.Type]func(context.Context, T) error
+	fallback func(context.Context, T) error
+}
+
+// NewDispatcher creates a new Dispatcher decoding through the given registry
+func NewDispatcher[T core.TypeCarrier](registry *TypeRegistry[T]) *Dispatcher[T] {
+	return &Dispatcher[T]{
+		registry: registry,
+		handlers: map[reflect.Type]func(context.Context, T) error{},
+	}
+}
+
+// Handle registers a handler for one concrete type.
+//
+// The handler's parameter type is what the registry decodes, i.e. a pointer
+// to the registered struct. This is a function rather than a method because
+// each handler carries its own type parameter.
+func Handle[O any, T core.TypeCarrier](dispatcher *Dispatcher[T], handler func(context.Context, O) error) *Dispatcher[T] {
+	dispatcher.handlers[reflect.TypeOf((*O)(nil)).Elem()] = func(ctx context.Context, object T) error {
+		return handler(ctx, any(object).(O))
+	}
+	return dispatcher
+}
+
+// WithFallbackHandler registers a handler for objects whose concrete type
+// has no handler of its own
+func (dispatcher *Dispatcher[T]) WithFallbackHandler(handler func(context.Context, T) error) *Dispatcher[T] {
+	dispatcher.fallback = handler
+	return dispatcher
+}
+
+// Dispatch decodes the given payload and routes the object to the handler
+// matching its concrete type.
+//
+// Objects of a type without a handler go to the fallback handler; without
+// one, an errors.InvalidType listing the handled types is returned. Decode
+// failures carry the usual registry errors.
+func (dispatcher *Dispatcher[T]) Dispatch(ctx context.Context, payload []byte) error {
+	object, err := dispatcher.registry.UnmarshalContext(ctx, payload)
+	if err != nil {
+		return err
+	}
+	if handler, found := dispatcher.handlers[reflect.TypeOf(object)]; found {
+		return handler(ctx, object)
+	}
+	if dispatcher.fallback != nil {
+		return dispatcher.fallback(ctx, object)
+	}
+	return errors.InvalidType.With(fmt.Sprintf("%T", object), dispatcher.handledTypes())
+}
+
+// handledTypes gives the sorted concrete types the dispatcher handles
+func (dispatcher *Dispatcher[T]) handledTypes() []string {
+	types := make([]string, 0, len(dispatcher.handlers))
+	for class := range dispatcher.handlers {
+		types = append(types, class.String())
+	}
+	sort.Strings(types)
+	return types
+}
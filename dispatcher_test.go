@@ -0,0 +1,64 @@
+package argo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestDispatcherRoutesToTypedHandlers(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+	dispatcher := argo.NewDispatcher(registry)
+
+	var received *Something1
+	argo.Handle(dispatcher, func(ctx context.Context, object *Something1) error {
+		received = object
+		return nil
+	})
+	argo.Handle(dispatcher, func(ctx context.Context, object *Something2) error {
+		t.Fatal("the Something2 handler should not be called")
+		return nil
+	})
+
+	if err := dispatcher.Dispatch(context.Background(), []byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Fatalf("failed to dispatch: %s", err)
+	}
+	if received == nil || received.Text != "hello" {
+		t.Errorf("unexpected object: %+v", received)
+	}
+}
+
+func TestDispatcherUsesFallbackHandler(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+	dispatcher := argo.NewDispatcher(registry)
+
+	argo.Handle(dispatcher, func(ctx context.Context, object *Something1) error { return nil })
+
+	err := dispatcher.Dispatch(context.Background(), []byte(`{"type": "something2", "value": 7}`))
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+
+	fallbacks := 0
+	dispatcher.WithFallbackHandler(func(ctx context.Context, object Something) error {
+		fallbacks++
+		return nil
+	})
+	if err = dispatcher.Dispatch(context.Background(), []byte(`{"type": "something2", "value": 7}`)); err != nil {
+		t.Fatalf("failed to dispatch: %s", err)
+	}
+	if fallbacks != 1 {
+		t.Errorf("expected 1 fallback call, got %d", fallbacks)
+	}
+}
+
+func TestDispatcherReportsDecodeFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	dispatcher := argo.NewDispatcher(registry)
+
+	if err := dispatcher.Dispatch(context.Background(), []byte(`{"type": "bogus"}`)); !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
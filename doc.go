@@ -0,0 +1,7 @@
+// Package argo unmarshals polymorphic JSON payloads into Go types.
+//
+// Payloads identify the type they carry either through a discriminator
+// value (see TypeRegistry) or through the name of a property wrapping
+// the actual object (see PropertyRegistry). Concurrent-safe variants of
+// both registries are available (SyncTypeRegistry, SyncPropertyRegistry).
+package argo
@@ -0,0 +1,76 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gildas/argo/errors"
+)
+
+// envelopeConfig tells the registry which envelope fields carry the
+// discriminator and the payload
+type envelopeConfig struct {
+	typeField    string
+	payloadField string
+}
+
+// Envelope carries the metadata of an enveloped payload, i.e. everything
+// around the decoded object.
+type Envelope struct {
+	// Type is the discriminator carried by the envelope, before alias resolution
+	Type string
+	// Metadata is every envelope field except the discriminator and the payload
+	Metadata map[string]json.RawMessage
+}
+
+// WithEnvelope makes the registry decode enveloped payloads such as:
+//
+//	{"type": "something1", "timestamp": "...", "payload": {"text": "hello"}}
+//
+// The discriminator is read from typeField at the top level of the document
+// and only the value of payloadField is decoded into the registered type.
+// UnmarshalEnvelope additionally gives the remaining envelope fields. The
+// configured type tags are ignored while an envelope is configured.
+func (registry *TypeRegistry[T]) WithEnvelope(typeField, payloadField string) *TypeRegistry[T] {
+	registry.envelope = &envelopeConfig{typeField: typeField, payloadField: payloadField}
+	return registry
+}
+
+// UnmarshalEnvelope unmarshals an enveloped payload (see WithEnvelope) and
+// gives the envelope metadata alongside the decoded object.
+func (registry *TypeRegistry[T]) UnmarshalEnvelope(payload []byte) (T, *Envelope, error) {
+	return registry.unmarshalEnvelope(context.Background(), payload)
+}
+
+// unmarshalEnvelope decodes the envelope, extracts the discriminator and
+// decodes the payload field into the registered type
+func (registry *TypeRegistry[T]) unmarshalEnvelope(ctx context.Context, payload []byte) (object T, envelope *Envelope, err error) {
+	var guts map[string]json.RawMessage
+
+	if registry.envelope == nil {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("envelope"))
+	}
+	if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+		return object, nil, errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+	}
+	raw, found := guts[registry.envelope.typeField]
+	if !found {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(registry.envelope.typeField))
+	}
+	typename, err := decodeDiscriminator(raw)
+	if err != nil {
+		return object, nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	body, found := guts[registry.envelope.payloadField]
+	if !found {
+		return object, nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(registry.envelope.payloadField))
+	}
+	envelope = &Envelope{Type: typename, Metadata: map[string]json.RawMessage{}}
+	for key, value := range guts {
+		if key != registry.envelope.typeField && key != registry.envelope.payloadField {
+			envelope.Metadata[key] = value
+		}
+	}
+	object, err = registry.decodeResolved(ctx, typename, body, guts)
+	return object, envelope, err
+}
@@ -0,0 +1,72 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCanUnmarshalEnvelopes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().
+		Add(Something1{}, Something2{}).
+		WithEnvelope("type", "payload")
+
+	object, envelope, err := registry.UnmarshalEnvelope([]byte(`{"type": "something1", "timestamp": "2026-08-31T12:00:00Z", "payload": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if envelope.Type != "something1" {
+		t.Errorf(`expected envelope type "something1", got %q`, envelope.Type)
+	}
+	if raw, found := envelope.Metadata["timestamp"]; !found {
+		t.Error("envelope should carry the timestamp")
+	} else if string(raw) != `"2026-08-31T12:00:00Z"` {
+		t.Errorf("unexpected timestamp: %s", raw)
+	}
+	if _, found := envelope.Metadata["payload"]; found {
+		t.Error("envelope metadata should not carry the payload")
+	}
+}
+
+func TestEnvelopeRegistryRoutesUnmarshal(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().
+		Add(Something1{}).
+		WithEnvelope("kind", "data")
+
+	object, err := registry.Unmarshal([]byte(`{"kind": "something1", "data": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestEnvelopeShouldFailWithoutItsFields(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().
+		Add(Something1{}).
+		WithEnvelope("type", "payload")
+
+	if _, _, err := registry.UnmarshalEnvelope([]byte(`{"payload": {"text": "hello"}}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+	if _, _, err := registry.UnmarshalEnvelope([]byte(`{"type": "something1"}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestEnvelopeShouldFailOnUnconfiguredRegistries(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if _, _, err := registry.UnmarshalEnvelope([]byte(`{"type": "something1", "payload": {}}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
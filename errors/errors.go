@@ -0,0 +1,142 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error describes a rich error template.
+//
+// Templates are declared as sentinel values (see JSONUnmarshalError,
+// InvalidType, etc.) and turned into actual errors with With and Wrap.
+// Two Errors match with errors.Is when they share the same ID.
+type Error struct {
+	// Code is an HTTP-like status code classifying the error
+	Code int
+	// ID identifies the error uniquely, e.g. "error.json.unmarshal"
+	ID string
+	// Text is the format of the error message, its verbs consume What
+	Text string
+	// What carries the values the error is about, e.g. a type name
+	What []any
+	// Cause is the error that provoked this error, if any
+	Cause error
+}
+
+var (
+	// ArgumentMissing is used when an expected argument or field is absent
+	ArgumentMissing = Error{Code: 400, ID: "error.argument.missing", Text: "Argument %s is missing"}
+
+	// ArgumentInvalid is used when an argument or field carries an invalid value
+	ArgumentInvalid = Error{Code: 400, ID: "error.argument.invalid", Text: "Argument %s is invalid (value: %v)"}
+
+	// InvalidType is used when a type name does not match any known type
+	InvalidType = Error{Code: 400, ID: "error.type.invalid", Text: "Invalid Type: %s, supported types: %v"}
+
+	// JSONUnmarshalError is used when data failed to be unmarshaled
+	JSONUnmarshalError = Error{Code: 400, ID: "error.json.unmarshal", Text: "JSON failed to unmarshal data"}
+
+	// JSONMarshalError is used when data failed to be marshaled
+	JSONMarshalError = Error{Code: 500, ID: "error.json.marshal", Text: "JSON failed to marshal data"}
+
+	// XMLUnmarshalError is used when XML data failed to be unmarshaled
+	XMLUnmarshalError = Error{Code: 400, ID: "error.xml.unmarshal", Text: "XML failed to unmarshal data"}
+
+	// UnmarshalError is used when data in a non-JSON wire format failed to
+	// be unmarshaled; codecs wrap their decode failures in it
+	UnmarshalError = Error{Code: 400, ID: "error.unmarshal", Text: "Failed to unmarshal data"}
+
+	// MarshalError is used when data failed to be marshaled into a non-JSON
+	// wire format
+	MarshalError = Error{Code: 500, ID: "error.marshal", Text: "Failed to marshal data"}
+
+	// TypeMismatch is used when a payload's discriminator contradicts an
+	// out-of-band assertion about its type
+	TypeMismatch = Error{Code: 403, ID: "error.type.mismatch", Text: "Type mismatch: payload carries %s, assertion claims %s"}
+
+	// SchemaViolation is used when a payload does not conform to its schema
+	SchemaViolation = Error{Code: 400, ID: "error.schema.violation", Text: "Schema violation at %s: %s"}
+
+	// IntegrityError is used when a payload fails its integrity check
+	IntegrityError = Error{Code: 400, ID: "error.integrity", Text: "Integrity check failed for %s"}
+
+	// UnsupportedMediaType is used when a request body carries a content
+	// type the decoder does not support
+	UnsupportedMediaType = Error{Code: 415, ID: "error.mediatype.unsupported", Text: "Unsupported media type: %s, supported types: %v"}
+
+	// RequestTooLarge is used when a request body exceeds the configured size limit
+	RequestTooLarge = Error{Code: 413, ID: "error.request.toolarge", Text: "Request body exceeds %d bytes"}
+
+	// RegistryFrozen is used when a frozen registry is asked to change its registrations
+	RegistryFrozen = Error{Code: 403, ID: "error.registry.frozen", Text: "Registry is frozen"}
+
+	// NotImplemented is used when a feature is not yet implemented
+	NotImplemented = Error{Code: 501, ID: "error.notimplemented", Text: "Not Implemented"}
+)
+
+// With gives the error the values its message is about
+func (e Error) With(values ...any) Error {
+	final := e
+	final.What = values
+	return final
+}
+
+// Wrap attaches a cause to the error.
+//
+// Wrap never loses the template identity, so errors.Is still matches
+// the wrapping template as well as the cause.
+func (e Error) Wrap(err error) error {
+	final := e
+	final.Cause = err
+	return final
+}
+
+// Error gives the error message, satisfying the error interface
+func (e Error) Error() string {
+	var sb strings.Builder
+
+	if strings.Contains(e.Text, "%") {
+		fmt.Fprintf(&sb, e.Text, e.What...)
+	} else {
+		sb.WriteString(e.Text)
+	}
+	if e.Cause != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Cause.Error())
+	}
+	return sb.String()
+}
+
+// Is tells if the error matches the given target.
+//
+// Two Errors match when they bear the same ID.
+func (e Error) Is(target error) bool {
+	if actual, ok := target.(Error); ok {
+		return e.ID == actual.ID
+	}
+	return false
+}
+
+// Unwrap gives the cause of the error, if any
+func (e Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether any error in err's chain matches target.
+//
+// This is a convenience so callers do not need to import both this
+// package and the standard errors package.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
@@ -0,0 +1,64 @@
+// Command streamconsumer is a small reference consumer for NDJSON event
+// streams built on argo.
+//
+// It reads newline-delimited JSON from stdin, decodes each line through a
+// TypeRegistry, skips and reports bad lines, and prints what it decoded:
+//
+//	go run ./examples/streamconsumer < events.ndjson
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+)
+
+// Event is the family of payloads this consumer accepts
+type Event interface {
+	core.TypeCarrier
+}
+
+// OrderPlaced is appended when an order is placed
+type OrderPlaced struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func (event OrderPlaced) GetType() string {
+	return "order.placed"
+}
+
+// OrderShipped is appended when an order ships
+type OrderShipped struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func (event OrderShipped) GetType() string {
+	return "order.shipped"
+}
+
+// newRegistry builds the registry this consumer decodes with
+func newRegistry() *argo.TypeRegistry[Event] {
+	return argo.NewTypeRegistry[Event]().Add(OrderPlaced{}, OrderShipped{})
+}
+
+func main() {
+	registry := newRegistry()
+	options := argo.NDJSONOptions{
+		SkipOnError: true,
+		OnError: func(line int, payload []byte, err error) {
+			log.Printf("line %d: %s", line, err)
+		},
+	}
+	err := registry.ReadNDJSON(os.Stdin, options, func(event Event) error {
+		log.Printf("decoded %s", event.GetType())
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
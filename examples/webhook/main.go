@@ -0,0 +1,102 @@
+// Command webhook is a small reference webhook receiver built on argo.
+//
+// It decodes polymorphic events from POST /events through a TypeRegistry,
+// throttles abusive senders with a ThrottlePolicy, and fans decoded events
+// out to typed handlers through an AsyncDispatcher:
+//
+//	go run ./examples/webhook
+//	curl -d '{"type": "user.created", "id": "U1"}' http://localhost:8080/events
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+)
+
+// Event is the family of payloads this receiver accepts
+type Event interface {
+	core.TypeCarrier
+}
+
+// UserCreated is sent when a user signs up
+type UserCreated struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func (event UserCreated) GetType() string {
+	return "user.created"
+}
+
+// UserDeleted is sent when a user closes their account
+type UserDeleted struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func (event UserDeleted) GetType() string {
+	return "user.deleted"
+}
+
+// newRegistry builds the registry this receiver decodes with
+func newRegistry() *argo.TypeRegistry[Event] {
+	return argo.NewTypeRegistry[Event]().Add(UserCreated{}, UserDeleted{})
+}
+
+// newDispatcher builds the dispatcher decoded events are fanned out to
+func newDispatcher(registry *argo.TypeRegistry[Event]) *argo.AsyncDispatcher[Event] {
+	return argo.NewAsyncDispatcher(registry, 4, func(ctx context.Context, event Event) error {
+		log.Printf("handled %s", event.GetType())
+		return nil
+	})
+}
+
+// newReceiver builds the HTTP handler: throttled decode, then dispatch
+func newReceiver(registry *argo.TypeRegistry[Event], dispatcher *argo.AsyncDispatcher[Event]) http.Handler {
+	throttle := &argo.ThrottlePolicy{Requests: 100, Window: time.Minute, MaxConcurrent: 10}
+	mux := http.NewServeMux()
+	mux.Handle("/events", throttle.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if _, err := registry.UnmarshalContext(r.Context(), payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := dispatcher.Dispatch(r.Context(), payload); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})))
+	return mux
+}
+
+func main() {
+	registry := newRegistry()
+	dispatcher := newDispatcher(registry)
+	ctx := context.Background()
+	dispatcher.Start(ctx)
+	defer func() {
+		shutdown, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		_ = dispatcher.Close(shutdown)
+	}()
+
+	log.Println("listening on :8080")
+	if err := http.ListenAndServe(":8080", newReceiver(registry, dispatcher)); err != nil {
+		log.Fatal(err)
+	}
+}
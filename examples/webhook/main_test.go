@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+)
+
+func startDispatcher(t *testing.T, registry *argo.TypeRegistry[Event]) *argo.AsyncDispatcher[Event] {
+	t.Helper()
+	dispatcher := newDispatcher(registry)
+	dispatcher.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = dispatcher.Close(ctx)
+	})
+	return dispatcher
+}
+
+func TestReceiverAcceptsKnownEvents(t *testing.T) {
+	registry := newRegistry()
+	receiver := newReceiver(registry, startDispatcher(t, registry))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"type": "user.created", "id": "U1"}`))
+	receiver.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusAccepted {
+		t.Errorf("expected 202, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestReceiverRejectsUnknownEvents(t *testing.T) {
+	registry := newRegistry()
+	receiver := newReceiver(registry, startDispatcher(t, registry))
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"type": "whatever"}`))
+	receiver.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", recorder.Code)
+	}
+}
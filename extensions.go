@@ -0,0 +1,104 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gildas/argo/errors"
+)
+
+// This file defines the extension points optional sub-packages implement.
+//
+// The root package must stay dependency-light: it imports nothing but the
+// standard library (and its own core and errors packages). Integrations
+// that need third-party modules (YAML or msgpack codecs, OpenTelemetry,
+// broker clients, ...) live in sub-packages and plug into the interfaces
+// below, so their transitive dependencies are only pulled by the services
+// that actually use them. See ARCHITECTURE.md.
+
+// Codec translates between a wire format and Go values.
+//
+// The registries use a Codec in two steps: Keys exposes the top level of a
+// document so the discriminator can be found, Unmarshal then decodes the
+// whole document into the matched type. JSON is built in (JSONCodec);
+// alternative formats implement this interface in their own sub-package.
+type Codec interface {
+	// Name gives the name of the format, e.g. "json"
+	Name() string
+	// Keys decodes the top level of a document into raw sub-documents
+	Keys(payload []byte) (map[string][]byte, error)
+	// Unmarshal decodes a document into the given value
+	Unmarshal(payload []byte, value any) error
+	// Marshal encodes the given value into a document
+	Marshal(value any) ([]byte, error)
+}
+
+// Observer is notified of decode activity.
+//
+// Implementations typically feed metrics or tracing systems; they must be
+// safe for concurrent use and fast, as they run on the decode path.
+type Observer interface {
+	// ObserveUnmarshal is called after every decode attempt
+	ObserveUnmarshal(typename string, duration time.Duration, err error)
+}
+
+// WithObserver makes the registry report every decode attempt to the given
+// observer.
+//
+// The observer receives the decoded type name, the decode duration and the
+// error, if any; failures that happen before the discriminator is resolved
+// report an empty type name. Prometheus counters and histograms for
+// per-type decode and failure rates can thus be wired without wrapping
+// every call site.
+func (registry *TypeRegistry[T]) WithObserver(observer Observer) *TypeRegistry[T] {
+	registry.observer = observer
+	return registry
+}
+
+// Transport streams inbound messages to the pipeline components.
+//
+// Broker adapters (Kafka, NATS, AMQP, ...) implement it in their own
+// modules; argo never imports broker clients itself.
+type Transport interface {
+	// Deliveries streams inbound messages until the context is canceled
+	Deliveries(ctx context.Context) (<-chan Delivery, error)
+}
+
+// JSONCodec is the built-in Codec for JSON
+type JSONCodec struct{}
+
+// Name gives the name of the format
+func (codec JSONCodec) Name() string {
+	return "json"
+}
+
+// Keys decodes the top level of a document into raw sub-documents
+func (codec JSONCodec) Keys(payload []byte) (map[string][]byte, error) {
+	var guts map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &guts); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	keys := make(map[string][]byte, len(guts))
+	for key, raw := range guts {
+		keys[key] = raw
+	}
+	return keys, nil
+}
+
+// Unmarshal decodes a document into the given value
+func (codec JSONCodec) Unmarshal(payload []byte, value any) error {
+	if err := json.Unmarshal(payload, value); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	return nil
+}
+
+// Marshal encodes the given value into a document
+func (codec JSONCodec) Marshal(value any) ([]byte, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,46 @@
+package argo
+
+// Freeze seals the registry against further registrations.
+//
+// This lets a registry be built during init and guaranteed immutable at
+// runtime: after the call, Add and Remove panic with errors.RegistryFrozen,
+// and error-returning registration methods (AddAlias, AddVersion, ...)
+// return it. Decoding options and unmarshaling are unaffected. Freezing is
+// irreversible.
+func (registry *TypeRegistry[T]) Freeze() *TypeRegistry[T] {
+	registry.frozen = true
+	return registry
+}
+
+// Frozen tells whether the registry has been frozen
+func (registry *TypeRegistry[T]) Frozen() bool {
+	return registry.frozen
+}
+
+// Freeze seals the registry against further registrations (see
+// TypeRegistry.Freeze)
+func (registry *PropertyRegistry[T]) Freeze() *PropertyRegistry[T] {
+	registry.frozen = true
+	return registry
+}
+
+// Frozen tells whether the registry has been frozen
+func (registry *PropertyRegistry[T]) Frozen() bool {
+	return registry.frozen
+}
+
+// Freeze seals the registry against further registrations (see
+// TypeRegistry.Freeze)
+func (registry *SyncTypeRegistry[T]) Freeze() *SyncTypeRegistry[T] {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	next := registry.snapshot.Load().clone()
+	next.frozen = true
+	registry.snapshot.Store(next)
+	return registry
+}
+
+// Frozen tells whether the registry has been frozen
+func (registry *SyncTypeRegistry[T]) Frozen() bool {
+	return registry.snapshot.Load().frozen
+}
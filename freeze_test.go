@@ -0,0 +1,51 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestFrozenRegistryRejectsRegistrations(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).Freeze()
+
+	if !registry.Frozen() {
+		t.Error("registry should be frozen")
+	}
+	if err := registry.AddAlias("alias", "something1"); !errors.Is(err, errors.RegistryFrozen) {
+		t.Errorf("error should be a RegistryFrozen, got %v", err)
+	}
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Error("Add should have panicked")
+		}
+	}()
+	registry.Add(Something2{})
+}
+
+func TestFrozenRegistryStillUnmarshals(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).Freeze()
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestFrozenSyncRegistryRejectsRegistrations(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]("type").Add(Something1{}).Freeze()
+
+	if !registry.Frozen() {
+		t.Error("registry should be frozen")
+	}
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Error("Add should have panicked")
+		}
+	}()
+	registry.Add(Something2{})
+}
@@ -0,0 +1,91 @@
+package argo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// SwitchRegistry unmarshals payloads like a TypeRegistry, but its types are
+// named explicitly instead of implementing core.TypeCarrier.
+//
+// It is a migration aid: a legacy hand-written switch-based unmarshaler
+// moves onto argo in one step, without first adding GetType to dozens of
+// types (see FromSwitch). Decoded objects are returned as any, exactly like
+// the cases of the switch it replaces.
+type SwitchRegistry struct {
+	tags    []string
+	classes map[string]reflect.Type
+}
+
+// FromSwitch builds a registry from the cases of a hand-written switch.
+//
+// The map goes from discriminator to a zero value of the matching type:
+//
+//	registry := argo.FromSwitch(map[string]any{
+//	    "something1": Something1{},
+//	    "something2": Something2{},
+//	})
+//
+// The given tags are the JSON keys carrying the discriminator, tried in
+// order; without tags, the registry looks for the key "type".
+func FromSwitch(cases map[string]any, tags ...string) *SwitchRegistry {
+	if len(tags) == 0 {
+		tags = []string{"type"}
+	}
+	classes := make(map[string]reflect.Type, len(cases))
+	for name, instance := range cases {
+		classes[name] = dereferenceType(reflect.TypeOf(instance))
+	}
+	return &SwitchRegistry{
+		tags:    tags,
+		classes: classes,
+	}
+}
+
+// Size gives the number of types registered
+func (registry *SwitchRegistry) Size() int {
+	return len(registry.classes)
+}
+
+// Unmarshal unmarshals the given payload into the type matching its
+// discriminator, returned as a pointer wrapped in any.
+//
+// If the payload carries no type tag, an errors.ArgumentMissing is returned,
+// if the discriminator matches no registered type, an errors.InvalidType is
+// returned. Both are wrapped in an errors.JSONUnmarshalError.
+func (registry *SwitchRegistry) Unmarshal(payload []byte) (any, error) {
+	var guts map[string]json.RawMessage
+
+	if err := json.Unmarshal(payload, &guts); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	for _, tag := range registry.tags {
+		raw, found := findTag(guts, tag)
+		if !found {
+			continue
+		}
+		typename, err := decodeDiscriminator(raw)
+		if err != nil {
+			return nil, errors.JSONUnmarshalError.Wrap(err)
+		}
+		class, found := registry.classes[typename]
+		if !found {
+			names := make([]string, 0, len(registry.classes))
+			for name := range registry.classes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(typename, names))
+		}
+		value := reflect.New(class).Interface()
+		if err := json.Unmarshal(payload, value); err != nil {
+			return nil, errors.JSONUnmarshalError.Wrap(err)
+		}
+		return value, nil
+	}
+	return nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+}
@@ -0,0 +1,62 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+// legacyEvent has no GetType on purpose: FromSwitch is for types that never
+// implemented core.TypeCarrier
+type legacyEvent struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+type legacyAlert struct {
+	Kind  string `json:"kind"`
+	Level int    `json:"level"`
+}
+
+func TestFromSwitchDispatchesOnExplicitNames(t *testing.T) {
+	registry := argo.FromSwitch(map[string]any{
+		"event": legacyEvent{},
+		"alert": legacyAlert{},
+	}, "kind")
+
+	if registry.Size() != 2 {
+		t.Errorf("expected 2 registered types, got %d", registry.Size())
+	}
+	object, err := registry.Unmarshal([]byte(`{"kind": "event", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if event := object.(*legacyEvent); event.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, event.Text)
+	}
+}
+
+func TestFromSwitchFailsOnUnknownDiscriminators(t *testing.T) {
+	registry := argo.FromSwitch(map[string]any{"event": legacyEvent{}}, "kind")
+
+	_, err := registry.Unmarshal([]byte(`{"kind": "whatever"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestFromSwitchFailsWithoutDiscriminator(t *testing.T) {
+	registry := argo.FromSwitch(map[string]any{"event": legacyEvent{}}, "kind")
+
+	_, err := registry.Unmarshal([]byte(`{"text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
@@ -0,0 +1,52 @@
+package argo
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gildas/argo/core"
+)
+
+// The process-wide registries, one per interface type.
+var (
+	globalLock       sync.Mutex
+	globalRegistries = map[reflect.Type]any{}
+)
+
+// Register registers classes on the process-wide registry for T.
+//
+// Small applications can thus register their types from init() without
+// threading a registry instance through every package:
+//
+//	func init() {
+//		argo.Register[Event](UserCreated{}, UserDeleted{})
+//	}
+//
+// Larger applications should prefer explicit registry instances, which keep
+// registrations visible and testable in isolation.
+func Register[T core.TypeCarrier](classes ...T) {
+	DefaultRegistry[T]().Add(classes...)
+}
+
+// UnmarshalRegistered unmarshals the given payload through the process-wide
+// registry for T
+func UnmarshalRegistered[T core.TypeCarrier](payload []byte) (T, error) {
+	return DefaultRegistry[T]().Unmarshal(payload)
+}
+
+// DefaultRegistry gives the process-wide registry for T, creating it on
+// first use with the default "type" tag.
+//
+// There is exactly one registry per interface type T for the whole process;
+// it is a SyncTypeRegistry, safe for concurrent registration and decoding.
+func DefaultRegistry[T core.TypeCarrier]() *SyncTypeRegistry[T] {
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	globalLock.Lock()
+	defer globalLock.Unlock()
+	if registry, found := globalRegistries[key]; found {
+		return registry.(*SyncTypeRegistry[T])
+	}
+	registry := NewSyncTypeRegistry[T]()
+	globalRegistries[key] = registry
+	return registry
+}
@@ -0,0 +1,37 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCanUnmarshalThroughGlobalRegistry(t *testing.T) {
+	argo.Register[Something](Something1{})
+	defer argo.DefaultRegistry[Something]().Remove("something1")
+
+	object, err := argo.UnmarshalRegistered[Something]([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestGlobalRegistriesAreKeyedByInterface(t *testing.T) {
+	argo.Register[Something](Something1{})
+	defer argo.DefaultRegistry[Something]().Remove("something1")
+
+	// the Command interface gets a registry of its own
+	if _, err := argo.UnmarshalRegistered[Command]([]byte(`{"type": "something1"}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
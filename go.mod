@@ -0,0 +1,3 @@
+module github.com/gildas/argo
+
+go 1.21
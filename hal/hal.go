@@ -0,0 +1,93 @@
+// Package hal extracts HAL hypermedia sections from decoded objects.
+//
+// HAL (Hypertext Application Language) wraps resources with "_links" and
+// "_embedded" sections alongside the business fields. This adapter decodes
+// the resource through an argo registry as usual, then hands the hypermedia
+// sections to the object when it opts in via the Linkable and Embedder
+// interfaces.
+package hal
+
+import (
+	"encoding/json"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Link is one entry of a "_links" section
+type Link struct {
+	Href      string `json:"href"`
+	Templated bool   `json:"templated,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// Links maps link relations to their targets.
+//
+// HAL allows a relation to carry either one link or a list of links; both
+// forms are normalized to a list.
+type Links map[string][]Link
+
+// Linkable describes objects that want their "_links" section
+type Linkable interface {
+	SetLinks(links Links)
+}
+
+// Embedder describes objects that want their "_embedded" section
+type Embedder interface {
+	SetEmbedded(embedded map[string]json.RawMessage)
+}
+
+// UnmarshalJSON normalizes single links and link lists, satisfying the
+// json.Unmarshaler interface
+func (links *Links) UnmarshalJSON(payload []byte) error {
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	normalized := Links{}
+	for relation, value := range raw {
+		var many []Link
+		if err := json.Unmarshal(value, &many); err == nil {
+			normalized[relation] = many
+			continue
+		}
+		var one Link
+		if err := json.Unmarshal(value, &one); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+		normalized[relation] = []Link{one}
+	}
+	*links = normalized
+	return nil
+}
+
+// envelope carries the hypermedia sections of a HAL document
+type envelope struct {
+	Links    Links                      `json:"_links"`
+	Embedded map[string]json.RawMessage `json:"_embedded"`
+}
+
+// Unmarshal unmarshals a HAL document through the given registry.
+//
+// The document is dispatched and decoded as usual; if the decoded object
+// implements Linkable or Embedder, it is also given its "_links" and
+// "_embedded" sections.
+func Unmarshal[T core.TypeCarrier](registry *argo.TypeRegistry[T], payload []byte) (object T, err error) {
+	if object, err = registry.Unmarshal(payload); err != nil {
+		return object, err
+	}
+	var sections envelope
+	if err = json.Unmarshal(payload, &sections); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if linkable, ok := any(object).(Linkable); ok && len(sections.Links) > 0 {
+		linkable.SetLinks(sections.Links)
+	}
+	if embedder, ok := any(object).(Embedder); ok && len(sections.Embedded) > 0 {
+		embedder.SetEmbedded(sections.Embedded)
+	}
+	return object, nil
+}
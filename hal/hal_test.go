@@ -0,0 +1,78 @@
+package hal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/hal"
+)
+
+type Resource interface {
+	core.TypeCarrier
+}
+
+type Order struct {
+	Type     string `json:"type"`
+	Total    int    `json:"total"`
+	links    hal.Links
+	embedded map[string]json.RawMessage
+}
+
+func (order Order) GetType() string {
+	return "order"
+}
+
+func (order *Order) SetLinks(links hal.Links) {
+	order.links = links
+}
+
+func (order *Order) SetEmbedded(embedded map[string]json.RawMessage) {
+	order.embedded = embedded
+}
+
+func TestHALExtractsLinksAndEmbedded(t *testing.T) {
+	registry := argo.NewTypeRegistry[Resource]().Add(Order{})
+
+	payload := `{
+		"type": "order",
+		"total": 30,
+		"_links": {
+			"self": {"href": "/orders/123"},
+			"items": [{"href": "/orders/123/items/1"}, {"href": "/orders/123/items/2"}]
+		},
+		"_embedded": {
+			"customer": {"type": "customer", "name": "Alice"}
+		}
+	}`
+	object, err := hal.Unmarshal(registry, []byte(payload))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	order := object.(*Order)
+	if order.Total != 30 {
+		t.Errorf("expected 30, got %d", order.Total)
+	}
+	if self := order.links["self"]; len(self) != 1 || self[0].Href != "/orders/123" {
+		t.Errorf("unexpected self link: %v", self)
+	}
+	if items := order.links["items"]; len(items) != 2 {
+		t.Errorf("expected 2 item links, got %d", len(items))
+	}
+	if _, found := order.embedded["customer"]; !found {
+		t.Error("expected an embedded customer")
+	}
+}
+
+func TestHALLeavesPlainObjectsAlone(t *testing.T) {
+	registry := argo.NewTypeRegistry[Resource]().Add(Order{})
+
+	object, err := hal.Unmarshal(registry, []byte(`{"type": "order", "total": 10}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if order := object.(*Order); order.Total != 10 {
+		t.Errorf("expected 10, got %d", order.Total)
+	}
+}
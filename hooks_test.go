@@ -0,0 +1,84 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestOnUnmarshalRunsAfterEveryDecode(t *testing.T) {
+	seen := []string{}
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{}).
+		OnUnmarshal(func(typename string, object Something) error {
+			seen = append(seen, typename)
+			if something1, ok := object.(*Something1); ok {
+				something1.Text = strings.ToLower(something1.Text)
+			}
+			return nil
+		})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "HELLO"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if len(seen) != 1 || seen[0] != "something1" {
+		t.Errorf("unexpected hook invocations: %v", seen)
+	}
+}
+
+func TestOnUnmarshalTypeOnlyRunsForItsType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	count := 0
+	if err := registry.OnUnmarshalType("something1", func(Something) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to register hook: %s", err)
+	}
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 3}`)); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 hook invocation, got %d", count)
+	}
+}
+
+func TestOnUnmarshalTypeRequiresARegisteredType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	err := registry.OnUnmarshalType("whatever", func(Something) error { return nil })
+	if err == nil {
+		t.Fatal("should have failed to register hook")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestOnUnmarshalFailuresFailTheDecode(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		OnUnmarshal(func(string, Something) error {
+			return errors.ArgumentInvalid.With("text", "hello")
+		})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+	if !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should wrap the hook failure, got %s", err)
+	}
+}
@@ -0,0 +1,124 @@
+package argo
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// DefaultMaxBodySize is the request body size limit FromRequest applies
+// when none is configured
+const DefaultMaxBodySize = 1 << 20 // 1 MiB
+
+// RequestOption configures FromRequest
+type RequestOption func(*requestOptions)
+
+// requestOptions collects what the request options configure
+type requestOptions struct {
+	maxBodySize  int64
+	contentTypes []string
+}
+
+// WithMaxBodySize sets the request body size limit, in bytes
+func WithMaxBodySize(size int64) RequestOption {
+	return func(options *requestOptions) {
+		options.maxBodySize = size
+	}
+}
+
+// WithContentTypes sets the accepted media types (by default application/json)
+func WithContentTypes(contentTypes ...string) RequestOption {
+	return func(options *requestOptions) {
+		options.contentTypes = contentTypes
+	}
+}
+
+// FromRequest decodes the body of an HTTP request through the given registry.
+//
+// The request's Content-Type must be one of the accepted media types (by
+// default application/json, see WithContentTypes) or an
+// errors.UnsupportedMediaType is returned, suitable for a 415 response.
+// Bodies larger than the configured limit (see WithMaxBodySize) fail with an
+// errors.RequestTooLarge; decode failures carry the usual registry errors,
+// suitable for a 400 response.
+func FromRequest[T core.TypeCarrier](registry Registry[T], r *http.Request, options ...RequestOption) (object T, err error) {
+	config := requestOptions{
+		maxBodySize:  DefaultMaxBodySize,
+		contentTypes: []string{"application/json"},
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	mediatype, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return object, errors.UnsupportedMediaType.With(r.Header.Get("Content-Type"), config.contentTypes)
+	}
+	accepted := false
+	for _, contentType := range config.contentTypes {
+		if mediatype == contentType {
+			accepted = true
+			break
+		}
+	}
+	if !accepted {
+		return object, errors.UnsupportedMediaType.With(mediatype, config.contentTypes)
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, config.maxBodySize))
+	if err != nil {
+		var toolarge *http.MaxBytesError
+		if errors.As(err, &toolarge) {
+			return object, errors.RequestTooLarge.With(config.maxBodySize)
+		}
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return registry.Unmarshal(payload)
+}
+
+// WriteJSON writes an object as a JSON response through the registry.
+//
+// The object is marshaled with ToMap, so its discriminator is always present
+// regardless of the struct's json tags, and the Content-Type is set before
+// the given status is written. Marshal failures are written as error bodies
+// instead (see WriteError) and returned.
+func WriteJSON[T core.TypeCarrier](w http.ResponseWriter, registry *TypeRegistry[T], object T, status int) error {
+	data, err := registry.ToMap(object)
+	if err != nil {
+		WriteError(w, err)
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		err = errors.JSONMarshalError.Wrap(err)
+		WriteError(w, err)
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(payload)
+	return err
+}
+
+// WriteError writes an error as a structured JSON response.
+//
+// The status is mapped from the argo error sentinel carried by the error
+// (e.g. 415 for errors.UnsupportedMediaType, 413 for errors.RequestTooLarge,
+// 400 for decode failures); errors carrying no sentinel are written as 500.
+// The body carries the error message and, when available, the sentinel ID.
+func WriteError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	body := map[string]any{"error": err.Error()}
+	var details errors.Error
+	if errors.As(err, &details) {
+		if details.Code > 0 {
+			status = details.Code
+		}
+		body["id"] = details.ID
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,103 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCanDecodeRequests(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	r := httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	object, err := argo.FromRequest[Something](registry, r)
+	if err != nil {
+		t.Fatalf("failed to decode request: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestFromRequestRejectsUnsupportedMediaTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	r := httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "something1"}`))
+	r.Header.Set("Content-Type", "text/plain")
+
+	if _, err := argo.FromRequest[Something](registry, r); !errors.Is(err, errors.UnsupportedMediaType) {
+		t.Errorf("error should be an UnsupportedMediaType, got %s", err)
+	}
+
+	r = httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	r.Header.Set("Content-Type", "application/vnd.example+json")
+	if _, err := argo.FromRequest[Something](registry, r, argo.WithContentTypes("application/vnd.example+json")); err != nil {
+		t.Errorf("failed to decode request: %s", err)
+	}
+}
+
+func TestFromRequestLimitsBodySize(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	r := httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	if _, err := argo.FromRequest[Something](registry, r, argo.WithMaxBodySize(10)); !errors.Is(err, errors.RequestTooLarge) {
+		t.Errorf("error should be a RequestTooLarge, got %s", err)
+	}
+}
+
+func TestCanWriteJSONResponses(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("kind").Add(Something1{})
+
+	w := httptest.NewRecorder()
+	if err := argo.WriteJSON(w, registry, Something(&Something1{Text: "hello"}), 201); err != nil {
+		t.Fatalf("failed to write response: %s", err)
+	}
+	if w.Code != 201 {
+		t.Errorf("expected 201, got %d", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type: %q", contentType)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response should be JSON: %s", err)
+	}
+	if body["kind"] != "something1" {
+		t.Errorf("response should carry the discriminator, got %v", body)
+	}
+	if body["text"] != "hello" {
+		t.Errorf(`expected "hello", got %v`, body["text"])
+	}
+}
+
+func TestWriteErrorMapsSentinelsToStatusCodes(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{errors.UnsupportedMediaType.With("text/plain", []string{"application/json"}), 415},
+		{errors.RequestTooLarge.With(10), 413},
+		{errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("type")), 400},
+		{io.ErrUnexpectedEOF, 500},
+	}
+	for _, testcase := range cases {
+		w := httptest.NewRecorder()
+		argo.WriteError(w, testcase.err)
+		if w.Code != testcase.status {
+			t.Errorf("expected %d for %s, got %d", testcase.status, testcase.err, w.Code)
+		}
+	}
+}
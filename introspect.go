@@ -0,0 +1,86 @@
+package argo
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gildas/argo/errors"
+)
+
+// registeredType is one registration in the introspection document
+type registeredType struct {
+	Name    string   `json:"name"`
+	GoType  string   `json:"go"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// registryDocument is the introspection document the registries marshal to
+type registryDocument struct {
+	Tags  []string         `json:"tags,omitempty"`
+	Types []registeredType `json:"types"`
+}
+
+// MarshalJSON marshals the registry's registrations for introspection.
+//
+// The document lists the type tags, the registered names with their Go
+// types and their aliases, so operators can expose e.g. a /debug/registry
+// endpoint showing exactly what the service can decode.
+func (registry *TypeRegistry[T]) MarshalJSON() ([]byte, error) {
+	document := registryDocument{
+		Tags:  registry.Tags(),
+		Types: make([]registeredType, 0, len(registry.types)),
+	}
+	for _, name := range registry.types {
+		aliases := []string{}
+		for alias, target := range registry.aliases {
+			if target == name {
+				aliases = append(aliases, alias)
+			}
+		}
+		sort.Strings(aliases)
+		document.Types = append(document.Types, registeredType{
+			Name:    name,
+			GoType:  registry.classes[name].String(),
+			Aliases: aliases,
+		})
+	}
+	payload, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// MarshalJSON marshals the registry's registrations for introspection (see
+// TypeRegistry.MarshalJSON)
+func (registry *PropertyRegistry[T]) MarshalJSON() ([]byte, error) {
+	document := registryDocument{
+		Tags:  append([]string(nil), registry.tags...),
+		Types: make([]registeredType, 0, len(registry.classes)),
+	}
+	for _, name := range registry.Types() {
+		document.Types = append(document.Types, registeredType{
+			Name:   name,
+			GoType: registry.classes[name].String(),
+		})
+	}
+	payload, err := json.Marshal(document)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// MarshalJSON marshals the registry's registrations for introspection (see
+// TypeRegistry.MarshalJSON)
+func (registry *SyncTypeRegistry[T]) MarshalJSON() ([]byte, error) {
+	return registry.snapshot.Load().MarshalJSON()
+}
+
+// MarshalJSON marshals the registry's registrations for introspection (see
+// TypeRegistry.MarshalJSON)
+func (registry *SyncPropertyRegistry[T]) MarshalJSON() ([]byte, error) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return registry.registry.MarshalJSON()
+}
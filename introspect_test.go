@@ -0,0 +1,82 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestTypeRegistryMarshalsItsRegistrations(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+	if err := registry.AddAlias("msg", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	payload, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	var document struct {
+		Tags  []string `json:"tags"`
+		Types []struct {
+			Name    string   `json:"name"`
+			GoType  string   `json:"go"`
+			Aliases []string `json:"aliases"`
+		} `json:"types"`
+	}
+	if err := json.Unmarshal(payload, &document); err != nil {
+		t.Fatalf("failed to unmarshal the document: %s", err)
+	}
+	if len(document.Tags) != 1 || document.Tags[0] != "type" {
+		t.Errorf("unexpected tags: %v", document.Tags)
+	}
+	if len(document.Types) != 2 {
+		t.Fatalf("expected 2 types, got %d", len(document.Types))
+	}
+	if document.Types[0].Name != "something1" {
+		t.Errorf(`expected "something1", got %q`, document.Types[0].Name)
+	}
+	if document.Types[0].GoType != "argo_test.Something1" {
+		t.Errorf("unexpected Go type: %q", document.Types[0].GoType)
+	}
+	if len(document.Types[0].Aliases) != 1 || document.Types[0].Aliases[0] != "msg" {
+		t.Errorf("unexpected aliases: %v", document.Types[0].Aliases)
+	}
+}
+
+func TestPropertyRegistryMarshalsItsRegistrations(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{})
+
+	payload, err := json.Marshal(registry)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	var document struct {
+		Types []struct {
+			Name string `json:"name"`
+		} `json:"types"`
+	}
+	if err := json.Unmarshal(payload, &document); err != nil {
+		t.Fatalf("failed to unmarshal the document: %s", err)
+	}
+	if len(document.Types) != 1 || document.Types[0].Name != "something1" {
+		t.Errorf("unexpected types: %v", document.Types)
+	}
+}
+
+func TestSyncRegistriesMarshalTheirRegistrations(t *testing.T) {
+	for name, registry := range map[string]json.Marshaler{
+		"sync":     argo.NewSyncTypeRegistry[Something]("type").Add(Something1{}),
+		"syncprop": argo.NewSyncPropertyRegistry[Something]().Add(Something1{}),
+	} {
+		payload, err := json.Marshal(registry)
+		if err != nil {
+			t.Errorf("%s: failed to marshal: %s", name, err)
+			continue
+		}
+		if !json.Valid(payload) {
+			t.Errorf("%s: invalid document: %s", name, string(payload))
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package argo
+
+import (
+	"io"
+	"iter"
+
+	"github.com/gildas/argo/errors"
+)
+
+// All gives an iterator over the documents of the given reader.
+//
+// Each document is dispatched on the registry's discriminator, so callers
+// can range over large polymorphic streams without buffering them:
+//
+//	for object, err := range registry.All(file) {
+//	    if err != nil { ... }
+//	    ...
+//	}
+//
+// The iteration ends at the end of the stream, or after yielding the first
+// failed document.
+func (registry *TypeRegistry[T]) All(reader io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		decoder := registry.NewDecoder(reader)
+		for decoder.More() {
+			object, err := decoder.Decode()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if !yield(object, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package jsonapi unmarshals JSON:API documents through an argo registry.
+//
+// A JSON:API document wraps the resource in a "data" envelope: the
+// discriminator lives in "data.type", the business fields in
+// "data.attributes", and linked resources in "data.relationships". This
+// adapter dispatches on "data.type", decodes the attributes into the
+// registered struct, and copies relationship identifiers into fields tagged
+// `jsonapi:"relation,<name>"`:
+//
+//	type Article struct {
+//	    Title    string   `json:"title"`
+//	    AuthorID string   `jsonapi:"relation,author"`
+//	    TagIDs   []string `jsonapi:"relation,tags"`
+//	}
+package jsonapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// resourceIdentifier is the {type, id} pair JSON:API uses to link resources
+type resourceIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// relationship carries either one resource identifier or a list of them
+type relationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// resource is the "data" member of a JSON:API document
+type resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id"`
+	Attributes    json.RawMessage         `json:"attributes"`
+	Relationships map[string]relationship `json:"relationships"`
+}
+
+// document is a single-resource JSON:API document
+type document struct {
+	Data resource `json:"data"`
+}
+
+// Unmarshal unmarshals a single-resource JSON:API document.
+//
+// The resource is dispatched on its "data.type" through the given registry,
+// its "data.attributes" decoded into the registered struct, and its
+// relationships copied into `jsonapi:"relation,<name>"` fields (string for
+// to-one, []string for to-many).
+func Unmarshal[T core.TypeCarrier](registry *argo.TypeRegistry[T], payload []byte) (object T, err error) {
+	var doc document
+
+	if err = json.Unmarshal(payload, &doc); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if doc.Data.Type == "" {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("data.type"))
+	}
+	guts := map[string]json.RawMessage{}
+	if len(doc.Data.Attributes) > 0 {
+		if err = json.Unmarshal(doc.Data.Attributes, &guts); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	tags := registry.Tags()
+	if len(tags) == 0 {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("tags"))
+	}
+	discriminator, _ := json.Marshal(doc.Data.Type)
+	guts[tags[0]] = discriminator
+	merged, err := json.Marshal(guts)
+	if err != nil {
+		return object, errors.JSONMarshalError.Wrap(err)
+	}
+	if object, err = registry.Unmarshal(merged); err != nil {
+		return object, err
+	}
+	if err = mapRelationships(object, doc.Data.Relationships); err != nil {
+		return object, err
+	}
+	return object, nil
+}
+
+// mapRelationships copies relationship identifiers into the tagged fields
+// of the decoded object
+func mapRelationships(object any, relationships map[string]relationship) error {
+	if len(relationships) == 0 {
+		return nil
+	}
+	value := reflect.ValueOf(object)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	for index := 0; index < value.NumField(); index++ {
+		field := value.Type().Field(index)
+		name, found := relationName(field)
+		if !found {
+			continue
+		}
+		related, found := relationships[name]
+		if !found || len(related.Data) == 0 {
+			continue
+		}
+		if err := setRelation(value.Field(index), field.Name, related.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// relationName gives the relationship name a field is tagged with, if any
+func relationName(field reflect.StructField) (string, bool) {
+	parts := strings.Split(field.Tag.Get("jsonapi"), ",")
+	if len(parts) != 2 || parts[0] != "relation" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// setRelation assigns a relationship's identifiers to the given field
+func setRelation(field reflect.Value, fieldname string, data json.RawMessage) error {
+	if !field.CanSet() {
+		return errors.ArgumentInvalid.With(fieldname, "unexported field")
+	}
+	switch field.Kind() {
+	case reflect.String:
+		var identifier resourceIdentifier
+		if err := json.Unmarshal(data, &identifier); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+		field.SetString(identifier.ID)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return errors.ArgumentInvalid.With(fieldname, field.Type().String())
+		}
+		var identifiers []resourceIdentifier
+		if err := json.Unmarshal(data, &identifiers); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+		ids := make([]string, 0, len(identifiers))
+		for _, identifier := range identifiers {
+			ids = append(ids, identifier.ID)
+		}
+		field.Set(reflect.ValueOf(ids).Convert(field.Type()))
+	default:
+		return errors.ArgumentInvalid.With(fieldname, field.Kind().String())
+	}
+	return nil
+}
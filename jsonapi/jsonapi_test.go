@@ -0,0 +1,76 @@
+package jsonapi_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+	"github.com/gildas/argo/jsonapi"
+)
+
+type Resource interface {
+	core.TypeCarrier
+}
+
+type Article struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	AuthorID string   `jsonapi:"relation,author"`
+	TagIDs   []string `jsonapi:"relation,tags"`
+}
+
+func (article Article) GetType() string {
+	return "articles"
+}
+
+func TestJSONAPIDecodesAttributes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Resource]().Add(Article{})
+
+	payload := `{
+		"data": {
+			"type": "articles",
+			"id": "1",
+			"attributes": {"title": "JSON:API paints my bikeshed!"},
+			"relationships": {
+				"author": {"data": {"type": "people", "id": "9"}},
+				"tags": {"data": [{"type": "tags", "id": "2"}, {"type": "tags", "id": "5"}]}
+			}
+		}
+	}`
+	object, err := jsonapi.Unmarshal(registry, []byte(payload))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	article := object.(*Article)
+	if article.Title != "JSON:API paints my bikeshed!" {
+		t.Errorf("unexpected title: %q", article.Title)
+	}
+	if article.AuthorID != "9" {
+		t.Errorf(`expected author "9", got %q`, article.AuthorID)
+	}
+	if len(article.TagIDs) != 2 || article.TagIDs[0] != "2" || article.TagIDs[1] != "5" {
+		t.Errorf("unexpected tags: %v", article.TagIDs)
+	}
+}
+
+func TestJSONAPIRequiresADataType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Resource]().Add(Article{})
+
+	_, err := jsonapi.Unmarshal(registry, []byte(`{"data": {"attributes": {"title": "whatever"}}}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestJSONAPIFailsOnUnknownTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Resource]().Add(Article{})
+
+	payload := `{"data": {"type": "people", "attributes": {}}}`
+	if _, err := jsonapi.Unmarshal(registry, []byte(payload)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
@@ -0,0 +1,145 @@
+// Package jsonrpc unmarshals JSON-RPC 2.0 messages through an argo registry.
+//
+// Requests and notifications are dispatched on their "method" member: the
+// params of a method are decoded into the type registered under the method
+// name in a TypeRegistry. Responses and batches are recognized as well, so
+// websocket RPC servers can run their entire inbound traffic through one
+// decoder:
+//
+//	registry := argo.NewTypeRegistry[Params]().Add(SubscribeParams{})
+//	message, err := jsonrpc.Unmarshal(registry, payload)
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Kind tells the shape of a JSON-RPC message
+type Kind string
+
+const (
+	// RequestKind is a call expecting a response (it carries an id)
+	RequestKind Kind = "request"
+	// NotificationKind is a call without an id, expecting no response
+	NotificationKind Kind = "notification"
+	// ResponseKind is the result or error of an earlier call
+	ResponseKind Kind = "response"
+)
+
+// Error is the error member of a JSON-RPC response
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error satisfies the error interface
+func (rpcerr Error) Error() string {
+	return rpcerr.Message
+}
+
+// Message is one decoded JSON-RPC message
+type Message[T core.TypeCarrier] struct {
+	// Kind tells whether this is a request, a notification or a response
+	Kind Kind
+	// ID is the raw id member, absent on notifications
+	ID json.RawMessage
+	// Method is the called method, on requests and notifications
+	Method string
+	// Params is the decoded params member, on requests and notifications
+	Params T
+	// Result is the raw result member, on successful responses
+	Result json.RawMessage
+	// Error is the error member, on failed responses
+	Error *Error
+}
+
+// wire is the common shape of every JSON-RPC 2.0 message
+type wire struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   *Error          `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// Unmarshal unmarshals a single JSON-RPC 2.0 message.
+//
+// The params of requests and notifications are dispatched on the "method"
+// member through the given registry; only by-name (object) params are
+// supported. Use UnmarshalBatch for batch payloads.
+func Unmarshal[T core.TypeCarrier](registry *argo.TypeRegistry[T], payload []byte) (message *Message[T], err error) {
+	var document wire
+
+	if err = json.Unmarshal(payload, &document); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if document.JSONRPC != "2.0" {
+		return nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("jsonrpc", document.JSONRPC))
+	}
+	if document.Method != "" {
+		message = &Message[T]{Kind: RequestKind, ID: document.ID, Method: document.Method}
+		if len(document.ID) == 0 || bytes.Equal(document.ID, []byte("null")) {
+			message.Kind = NotificationKind
+		}
+		if message.Params, err = decode(registry, document.Method, document.Params); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+	if len(document.Result) > 0 || document.Error != nil {
+		return &Message[T]{Kind: ResponseKind, ID: document.ID, Result: document.Result, Error: document.Error}, nil
+	}
+	return nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("method"))
+}
+
+// UnmarshalBatch unmarshals a JSON-RPC 2.0 batch, i.e. an array of messages.
+//
+// Messages are decoded in order; the first failing message fails the batch.
+func UnmarshalBatch[T core.TypeCarrier](registry *argo.TypeRegistry[T], payload []byte) (messages []*Message[T], err error) {
+	var elements []json.RawMessage
+
+	if err = json.Unmarshal(payload, &elements); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if len(elements) == 0 {
+		return nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("batch"))
+	}
+	messages = make([]*Message[T], 0, len(elements))
+	for _, element := range elements {
+		message, err := Unmarshal(registry, element)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// decode dispatches the params on the given method through the registry,
+// by merging the discriminator into the params document
+func decode[T core.TypeCarrier](registry *argo.TypeRegistry[T], method string, params json.RawMessage) (object T, err error) {
+	guts := map[string]json.RawMessage{}
+	if len(params) > 0 {
+		if err = json.Unmarshal(params, &guts); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("params", "by-name params expected"))
+		}
+	}
+	tags := registry.Tags()
+	if len(tags) == 0 {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("tags"))
+	}
+	discriminator, _ := json.Marshal(method)
+	guts[tags[0]] = discriminator
+	merged, err := json.Marshal(guts)
+	if err != nil {
+		return object, errors.JSONMarshalError.Wrap(err)
+	}
+	return registry.Unmarshal(merged)
+}
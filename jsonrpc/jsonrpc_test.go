@@ -0,0 +1,112 @@
+package jsonrpc_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+	"github.com/gildas/argo/jsonrpc"
+)
+
+type Params interface {
+	core.TypeCarrier
+}
+
+type SubscribeParams struct {
+	Method  string `json:"method"`
+	Channel string `json:"channel"`
+}
+
+func (params SubscribeParams) GetType() string {
+	return "subscribe"
+}
+
+func TestJSONRPCDecodesRequests(t *testing.T) {
+	registry := argo.NewTypeRegistry[Params]("method").Add(SubscribeParams{})
+
+	message, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "params": {"channel": "news"}, "id": 1}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if message.Kind != jsonrpc.RequestKind {
+		t.Errorf("expected a request, got %s", message.Kind)
+	}
+	if string(message.ID) != "1" {
+		t.Errorf("unexpected id: %s", message.ID)
+	}
+	params, ok := message.Params.(*SubscribeParams)
+	if !ok {
+		t.Fatalf("expected *SubscribeParams, got %T", message.Params)
+	}
+	if params.Channel != "news" {
+		t.Errorf(`expected channel "news", got %q`, params.Channel)
+	}
+}
+
+func TestJSONRPCDecodesNotifications(t *testing.T) {
+	registry := argo.NewTypeRegistry[Params]("method").Add(SubscribeParams{})
+
+	message, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "params": {"channel": "news"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if message.Kind != jsonrpc.NotificationKind {
+		t.Errorf("expected a notification, got %s", message.Kind)
+	}
+}
+
+func TestJSONRPCDecodesResponses(t *testing.T) {
+	registry := argo.NewTypeRegistry[Params]("method").Add(SubscribeParams{})
+
+	message, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "result": {"ok": true}, "id": 1}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if message.Kind != jsonrpc.ResponseKind {
+		t.Errorf("expected a response, got %s", message.Kind)
+	}
+
+	message, err = jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "error": {"code": -32601, "message": "method not found"}, "id": 1}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if message.Error == nil || message.Error.Code != -32601 {
+		t.Errorf("unexpected error member: %+v", message.Error)
+	}
+}
+
+func TestJSONRPCDecodesBatches(t *testing.T) {
+	registry := argo.NewTypeRegistry[Params]("method").Add(SubscribeParams{})
+
+	messages, err := jsonrpc.UnmarshalBatch(registry, []byte(`[
+		{"jsonrpc": "2.0", "method": "subscribe", "params": {"channel": "news"}, "id": 1},
+		{"jsonrpc": "2.0", "result": {"ok": true}, "id": 2}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].Kind != jsonrpc.RequestKind || messages[1].Kind != jsonrpc.ResponseKind {
+		t.Errorf("unexpected kinds: %s, %s", messages[0].Kind, messages[1].Kind)
+	}
+}
+
+func TestJSONRPCRejectsMalformedMessages(t *testing.T) {
+	registry := argo.NewTypeRegistry[Params]("method").Add(SubscribeParams{})
+
+	if _, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "1.0", "method": "subscribe"}`)); !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should be an ArgumentInvalid, got %s", err)
+	}
+	if _, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "id": 1}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+	if _, err := jsonrpc.Unmarshal(registry, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "params": [1, 2]}`)); !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should be an ArgumentInvalid, got %s", err)
+	}
+	if _, err := jsonrpc.UnmarshalBatch(registry, []byte(`[]`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+//go:build goexperiment.jsonv2
+
+package argo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"reflect"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// UnmarshalV2 unmarshals like Unmarshal through the json/v2 APIs.
+//
+// The discriminator is read with a streaming jsontext token scan that stops
+// at the first matching type tag, instead of materializing the whole payload
+// into a map[string]json.RawMessage, and the matched type is decoded with
+// jsonv2.Unmarshal; the payload is thus parsed once per pass instead of
+// twice in full.
+//
+// This is experimental, gated behind GOEXPERIMENT=jsonv2, and only covers
+// the common path: top-level type tags, aliases, case folding, the default
+// type, post-unmarshal hooks and validation. Nested tag paths, Strict,
+// UseNumber, checksums, schema validation and migrations keep going through
+// Unmarshal.
+func (registry *TypeRegistry[T]) UnmarshalV2(payload []byte) (object T, err error) {
+	typename, found, err := scanDiscriminatorV2(payload, registry.tags)
+	if err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if !found {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+	}
+	typename, class, known := registry.resolve(typename)
+	if !known {
+		if registry.defaultClass == nil {
+			return object, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(typename, registry.supportedTypes()))
+		}
+		class = registry.defaultClass
+	}
+	value := reflect.New(class).Interface()
+	if err = jsonv2.Unmarshal(payload, value); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	object = asRegistered[T](value)
+	if err = registry.applyUnmarshalHooks(typename, object); err != nil {
+		return object, err
+	}
+	return object, registry.validate(context.Background(), object)
+}
+
+// scanDiscriminatorV2 streams tokens through the top level of the payload
+// and gives the value of the first matching type tag, skipping over other
+// values without decoding them
+func scanDiscriminatorV2(payload []byte, tags []string) (string, bool, error) {
+	decoder := jsontext.NewDecoder(bytes.NewReader(payload))
+	token, err := decoder.ReadToken()
+	if err != nil {
+		return "", false, err
+	}
+	if token.Kind() != '{' {
+		return "", false, errors.ArgumentInvalid.With("payload", "not an object")
+	}
+	for {
+		token, err = decoder.ReadToken()
+		if err != nil {
+			return "", false, err
+		}
+		if token.Kind() == '}' {
+			return "", false, nil
+		}
+		name := token.String()
+		matched := false
+		for _, tag := range tags {
+			if name == tag {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if err = decoder.SkipValue(); err != nil {
+				return "", false, err
+			}
+			continue
+		}
+		token, err = decoder.ReadToken()
+		if err != nil {
+			return "", false, err
+		}
+		switch token.Kind() {
+		case '"', '0', 't', 'f':
+			return token.String(), true, nil
+		default:
+			return "", false, errors.ArgumentInvalid.With("type", token.String())
+		}
+	}
+}
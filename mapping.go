@@ -0,0 +1,67 @@
+package argo
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/gildas/argo/errors"
+)
+
+// LoadMapping reads a JSON document mapping wire discriminators to
+// registered type names and installs each entry as an alias.
+//
+// The document is a flat object, e.g.:
+//
+//	{
+//	    "user.created": "UserCreated",
+//	    "user.deleted": "UserDeleted"
+//	}
+//
+// Keeping the wire-name mapping as data lets non-Go stakeholders review it
+// without reading registration code. Every target must already be
+// registered; an unknown target or a conflicting discriminator fails the
+// whole load, so a bad mapping is caught at startup rather than on the
+// first payload.
+func (registry *TypeRegistry[T]) LoadMapping(reader io.Reader) error {
+	var mapping map[string]string
+
+	if err := json.NewDecoder(reader).Decode(&mapping); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	if registry.frozen {
+		return errors.RegistryFrozen
+	}
+	// validate every entry before installing anything, so a bad mapping
+	// leaves the registry untouched
+	aliases := map[string]string{}
+	for discriminator, typename := range mapping {
+		canonical, _, found := registry.resolve(typename)
+		if !found {
+			return errors.InvalidType.With(typename, registry.supportedTypes())
+		}
+		if discriminator == typename {
+			continue
+		}
+		if _, found := registry.classes[discriminator]; found {
+			return errors.ArgumentInvalid.With("alias", discriminator)
+		}
+		aliases[discriminator] = canonical
+	}
+	for discriminator, canonical := range aliases {
+		if err := registry.AddAlias(discriminator, canonical); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadMappingFile reads a mapping file (see LoadMapping) from the given path
+func (registry *TypeRegistry[T]) LoadMappingFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.ArgumentInvalid.With("path", path).Wrap(err)
+	}
+	defer file.Close()
+	return registry.LoadMapping(file)
+}
@@ -0,0 +1,69 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestLoadMappingInstallsAliases(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	mapping := `{"first": "something1", "second": "something2"}`
+	if err := registry.LoadMapping(strings.NewReader(mapping)); err != nil {
+		t.Fatalf("failed to load mapping: %s", err)
+	}
+	object, err := registry.Unmarshal([]byte(`{"type": "first", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestLoadMappingRejectsUnknownTargets(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	err := registry.LoadMapping(strings.NewReader(`{"first": "whatever"}`))
+	if err == nil {
+		t.Fatal("should have failed to load mapping")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestLoadMappingIgnoresIdentityEntries(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if err := registry.LoadMapping(strings.NewReader(`{"something1": "something1"}`)); err != nil {
+		t.Errorf("failed to load mapping: %s", err)
+	}
+}
+
+func TestLoadMappingFailsOnBadDocuments(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if err := registry.LoadMapping(strings.NewReader(`{`)); err == nil {
+		t.Error("should have failed to load mapping")
+	}
+}
+
+func TestLoadMappingLeavesRegistryUntouchedOnConflicts(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	mapping := `{"first": "something1", "something2": "something1"}`
+	err := registry.LoadMapping(strings.NewReader(mapping))
+	if err == nil {
+		t.Fatal("should have failed to load mapping")
+	}
+	if !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should be an ArgumentInvalid, got %s", err)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "first", "text": "hello"}`)); err == nil {
+		t.Error("no alias from a rejected mapping should have been installed")
+	}
+}
@@ -0,0 +1,40 @@
+package argo
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gildas/argo/core"
+)
+
+// objectKey is the context key the middleware stores decoded objects under;
+// being generic, every registered interface type gets its own key
+type objectKey[T core.TypeCarrier] struct{}
+
+// Middleware gives a net/http middleware that decodes request bodies
+// through the registry.
+//
+// The decoded object is stored in the request context, where handlers
+// retrieve it with FromContext, so they only deal with already-typed
+// objects. Requests that fail to decode are short-circuited with a JSON
+// error response whose status comes from the error's code (e.g. 415 for an
+// unsupported media type, 400 for a malformed body). The options are those
+// of FromRequest.
+func (registry *TypeRegistry[T]) Middleware(options ...RequestOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			object, err := FromRequest[T](registry, r, options...)
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), objectKey[T]{}, object)))
+		})
+	}
+}
+
+// FromContext retrieves the object the middleware decoded for this request
+func FromContext[T core.TypeCarrier](ctx context.Context) (T, bool) {
+	object, found := ctx.Value(objectKey[T]{}).(T)
+	return object, found
+}
@@ -0,0 +1,75 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestMiddlewareInjectsDecodedObjects(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	handler := registry.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		object, found := argo.FromContext[Something](r.Context())
+		if !found {
+			t.Fatal("context should carry the decoded object")
+		}
+		something1, ok := object.(*Something1)
+		if !ok {
+			t.Fatalf("expected a *Something1, got %T", object)
+		}
+		if something1.Text != "hello" {
+			t.Errorf(`expected "hello", got %q`, something1.Text)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	r := httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d (%s)", w.Code, w.Body)
+	}
+}
+
+func TestMiddlewareShortCircuitsOnFailure(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	handler := registry.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+
+	r := httptest.NewRequest("POST", "/things", strings.NewReader(`{"type": "bogus"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response should be JSON: %s", err)
+	}
+	if body["id"] != "error.json.unmarshal" {
+		t.Errorf("unexpected error id: %v", body["id"])
+	}
+
+	r = httptest.NewRequest("POST", "/things", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "text/plain")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415, got %d", w.Code)
+	}
+}
+
+func TestFromContextReportsMissingObjects(t *testing.T) {
+	if _, found := argo.FromContext[Something](httptest.NewRequest("GET", "/", nil).Context()); found {
+		t.Error("context should not carry an object")
+	}
+}
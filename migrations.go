@@ -0,0 +1,88 @@
+package argo
+
+import (
+	"encoding/json"
+
+	"github.com/gildas/argo/errors"
+)
+
+// MigrationTransform upgrades a payload from one schema version to the next
+type MigrationTransform func(payload json.RawMessage) (json.RawMessage, error)
+
+// migration is one registered upgrade step
+type migration struct {
+	to        string
+	transform MigrationTransform
+}
+
+// Migrate registers an upgrade step from one schema version to the next.
+//
+// On Unmarshal, a payload whose version has a registered step is upgraded
+// step by step until no step applies, then decoded as usual; its version
+// field is rewritten to the last version reached. Long-lived event stores
+// thus keep replaying old payloads into the current structs:
+//
+//	registry.Migrate("1", "2", renameLegacyField)
+//	registry.Migrate("2", "3", splitAddress)
+//
+// Only one step may start from a given version.
+func (registry *TypeRegistry[T]) Migrate(from string, to string, transform MigrationTransform) error {
+	if len(from) == 0 {
+		return errors.ArgumentMissing.With("from")
+	}
+	if from == to {
+		return errors.ArgumentInvalid.With("to", to)
+	}
+	if _, found := registry.migrations[from]; found {
+		return errors.ArgumentInvalid.With("from", from)
+	}
+	if registry.migrations == nil {
+		registry.migrations = map[string]migration{}
+	}
+	registry.migrations[from] = migration{to: to, transform: transform}
+	return nil
+}
+
+// migrate upgrades the given payload step by step until no migration
+// applies, giving back the upgraded payload and its reparsed guts
+func (registry *TypeRegistry[T]) migrate(payload []byte, guts map[string]json.RawMessage) ([]byte, map[string]json.RawMessage, error) {
+	raw, found := findTag(guts, registry.versionTagName())
+	if !found {
+		return payload, guts, nil
+	}
+	version, err := decodeDiscriminator(raw)
+	if err != nil {
+		return payload, guts, err
+	}
+	steps := 0
+	current := json.RawMessage(payload)
+	for {
+		step, found := registry.migrations[version]
+		if !found {
+			break
+		}
+		if steps++; steps > len(registry.migrations) {
+			return payload, guts, errors.IntegrityError.With("migrations")
+		}
+		if current, err = step.transform(current); err != nil {
+			return payload, guts, err
+		}
+		version = step.to
+		if registry.logger != nil {
+			registry.logger.Debug("migrated payload", "version", version)
+		}
+	}
+	if steps == 0 {
+		return payload, guts, nil
+	}
+	if err = json.Unmarshal(current, &guts); err != nil {
+		return payload, guts, err
+	}
+	if quoted, err := json.Marshal(version); err == nil {
+		guts[registry.versionTagName()] = quoted
+	}
+	if payload, err = json.Marshal(guts); err != nil {
+		return payload, guts, err
+	}
+	return payload, guts, nil
+}
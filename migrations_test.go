@@ -0,0 +1,95 @@
+package argo_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestMigrateUpgradesOldPayloads(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	err := registry.Migrate("1", "2", func(payload json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(bytes.Replace(payload, []byte(`"message"`), []byte(`"text"`), 1)), nil
+	})
+	if err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "1", "message": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestMigrateChainsSteps(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	rename := func(from, to string) argo.MigrationTransform {
+		return func(payload json.RawMessage) (json.RawMessage, error) {
+			return json.RawMessage(bytes.Replace(payload, []byte(from), []byte(to), 1)), nil
+		}
+	}
+	if err := registry.Migrate("1", "2", rename(`"msg"`, `"message"`)); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+	if err := registry.Migrate("2", "3", rename(`"message"`, `"text"`)); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "1", "msg": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestMigrateLeavesCurrentPayloadsAlone(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.Migrate("1", "2", func(payload json.RawMessage) (json.RawMessage, error) {
+		t.Error("migration should not run")
+		return payload, nil
+	}); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "2", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestMigrateRejectsDuplicateSteps(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	identity := func(payload json.RawMessage) (json.RawMessage, error) { return payload, nil }
+	if err := registry.Migrate("1", "2", identity); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+	if err := registry.Migrate("1", "3", identity); err == nil {
+		t.Error("should have failed to register migration")
+	}
+}
+
+func TestMigrateDetectsCycles(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	identity := func(payload json.RawMessage) (json.RawMessage, error) { return payload, nil }
+	if err := registry.Migrate("1", "2", identity); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+	if err := registry.Migrate("2", "1", identity); err != nil {
+		t.Fatalf("failed to register migration: %s", err)
+	}
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "1", "text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.IntegrityError) {
+		t.Errorf("error should be an IntegrityError, got %s", err)
+	}
+}
@@ -0,0 +1,63 @@
+package argo
+
+// Must gives the value and panics on error, for wrapping fallible calls in
+// variable initializers:
+//
+//	var config = argo.Must(argo.Unmarshal[Config](payload))
+func Must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// MustUnmarshal unmarshals like Unmarshal and panics on failure, for tests
+// and examples where error plumbing is pure noise
+func MustUnmarshal[T any](payload []byte) *T {
+	return Must(Unmarshal[T](payload))
+}
+
+// MustUnmarshal unmarshals like Unmarshal and panics on failure
+func (registry *TypeRegistry[T]) MustUnmarshal(payload []byte) T {
+	return Must(registry.Unmarshal(payload))
+}
+
+// MustAddAlias registers an alias like AddAlias and panics on failure; being
+// chainable, it suits init-time registration:
+//
+//	var registry = argo.NewTypeRegistry[Something]().
+//	    Add(Something1{}).
+//	    MustAddAlias("s1", "something1")
+func (registry *TypeRegistry[T]) MustAddAlias(alias string, typename string) *TypeRegistry[T] {
+	if err := registry.AddAlias(alias, typename); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// MustAddNumericAlias registers a numeric alias like AddNumericAlias and
+// panics on failure
+func (registry *TypeRegistry[T]) MustAddNumericAlias(value int64, typename string) *TypeRegistry[T] {
+	if err := registry.AddNumericAlias(value, typename); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// MustAddVersion registers a versioned type like AddVersion and panics on failure
+func (registry *TypeRegistry[T]) MustAddVersion(typename string, version string, class T) *TypeRegistry[T] {
+	if err := registry.AddVersion(typename, version, class); err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// MustUnmarshal unmarshals like Unmarshal and panics on failure
+func (registry *PropertyRegistry[T]) MustUnmarshal(payload []byte) T {
+	return Must(registry.Unmarshal(payload))
+}
+
+// MustUnmarshal unmarshals like Unmarshal and panics on failure
+func (registry *SyncTypeRegistry[T]) MustUnmarshal(payload []byte) T {
+	return Must(registry.Unmarshal(payload))
+}
@@ -0,0 +1,41 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestMustUnmarshalGivesTheObject(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").
+		Add(Something1{}).
+		MustAddAlias("s1", "something1")
+
+	object := registry.MustUnmarshal([]byte(`{"type": "s1", "text": "hello"}`))
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestMustVariantsPanicOnFailure(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	assertPanics := func(name string, callback func()) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				t.Errorf("%s should have panicked", name)
+				return
+			}
+			if _, ok := recovered.(error); !ok {
+				t.Errorf("%s should have panicked with an error, got %v", name, recovered)
+			}
+		}()
+		callback()
+	}
+
+	assertPanics("MustUnmarshal", func() { registry.MustUnmarshal([]byte(`{"type": "bogus"}`)) })
+	assertPanics("MustAddAlias", func() { registry.MustAddAlias("alias", "bogus") })
+	assertPanics("MustAddNumericAlias", func() { registry.MustAddNumericAlias(3, "bogus") })
+	assertPanics("MustAddVersion", func() { registry.MustAddVersion("something1", "", Something1{}) })
+}
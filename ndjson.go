@@ -0,0 +1,94 @@
+package argo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// NDJSONOptions configures how a newline-delimited JSON stream is read.
+type NDJSONOptions struct {
+	// SkipOnError keeps reading past lines that fail to decode, instead of
+	// stopping the stream at the first failure
+	SkipOnError bool
+	// OnError is told about every line that failed to decode, with its
+	// 1-based line number; it may be nil
+	OnError func(line int, payload []byte, err error)
+	// MaxLineSize caps the size of a single line in bytes (default: 1 MiB)
+	MaxLineSize int
+}
+
+// ReadNDJSON reads newline-delimited JSON from the given reader and hands
+// every decoded object to the given callback.
+//
+// Blank lines are skipped. Decode failures stop the stream unless the
+// options ask to skip-and-report; reader failures always stop the stream.
+// The callback may stop the stream early by returning an error, which is
+// passed through unwrapped.
+func (registry *TypeRegistry[T]) ReadNDJSON(reader io.Reader, options NDJSONOptions, callback func(T) error) error {
+	scanner := bufio.NewScanner(reader)
+	maxLineSize := options.MaxLineSize
+	if maxLineSize == 0 {
+		maxLineSize = 1024 * 1024
+	}
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		payload := bytes.TrimSpace(scanner.Bytes())
+		if len(payload) == 0 {
+			continue
+		}
+		object, err := registry.Unmarshal(payload)
+		if err != nil {
+			if options.OnError != nil {
+				options.OnError(line, payload, err)
+			}
+			if options.SkipOnError {
+				continue
+			}
+			return err
+		}
+		if err := callback(object); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	return nil
+}
+
+// StreamNDJSON reads newline-delimited JSON from the given reader and emits
+// decoded objects on the returned channel.
+//
+// The channel is closed when the stream ends, fails, or the context is
+// canceled; the returned function reports the terminal error, if any, once
+// the channel is closed.
+func StreamNDJSON[T core.TypeCarrier](ctx context.Context, registry *TypeRegistry[T], reader io.Reader, options NDJSONOptions) (<-chan T, func() error) {
+	objects := make(chan T, pipelineBuffer)
+	var failure error
+
+	done := make(chan struct{})
+	go func() {
+		defer close(objects)
+		defer close(done)
+		failure = registry.ReadNDJSON(reader, options, func(object T) error {
+			select {
+			case objects <- object:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return objects, func() error {
+		<-done
+		return failure
+	}
+}
@@ -0,0 +1,93 @@
+package argo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestReadNDJSONDecodesEveryLine(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	stream := `{"type": "something1", "text": "hello"}
+
+{"type": "something2", "value": 3}
+`
+	objects := []Something{}
+	err := registry.ReadNDJSON(strings.NewReader(stream), argo.NDJSONOptions{}, func(object Something) error {
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("expected 2 objects, got %d", len(objects))
+	}
+}
+
+func TestReadNDJSONStopsOnErrorByDefault(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	stream := `{"type": "whatever"}
+{"type": "something1", "text": "hello"}`
+	count := 0
+	err := registry.ReadNDJSON(strings.NewReader(stream), argo.NDJSONOptions{}, func(Something) error {
+		count++
+		return nil
+	})
+	if err == nil {
+		t.Error("should have failed to read")
+	}
+	if count != 0 {
+		t.Errorf("expected 0 objects, got %d", count)
+	}
+}
+
+func TestReadNDJSONSkipsAndReports(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	stream := `{"type": "whatever"}
+{"type": "something1", "text": "hello"}`
+	count, failed := 0, 0
+	options := argo.NDJSONOptions{
+		SkipOnError: true,
+		OnError: func(line int, payload []byte, err error) {
+			failed = line
+		},
+	}
+	err := registry.ReadNDJSON(strings.NewReader(stream), options, func(Something) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 object, got %d", count)
+	}
+	if failed != 1 {
+		t.Errorf("expected line 1 to be reported, got %d", failed)
+	}
+}
+
+func TestStreamNDJSONEmitsOnAChannel(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	stream := `{"type": "something1", "text": "hello"}
+{"type": "something2", "value": 3}`
+	objects, wait := argo.StreamNDJSON(context.Background(), registry, strings.NewReader(stream), argo.NDJSONOptions{})
+
+	count := 0
+	for range objects {
+		count++
+	}
+	if err := wait(); err != nil {
+		t.Fatalf("failed to read: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 objects, got %d", count)
+	}
+}
@@ -0,0 +1,85 @@
+package argo
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// ContentNegotiator feeds one registry from several wire formats, selecting
+// the codec by media type.
+//
+// JSON is handled natively; other formats are routed to the codec registered
+// for their media type, so one endpoint can accept e.g. JSON and msgpack for
+// the same polymorphic types:
+//
+//	negotiator := argo.NewContentNegotiator(registry).
+//	    WithCodec("application/msgpack", msgpack.Codec{})
+//	object, err := negotiator.Unmarshal(r.Header.Get("Content-Type"), payload)
+type ContentNegotiator[T core.TypeCarrier] struct {
+	registry *TypeRegistry[T]
+	codecs   map[string]Codec
+}
+
+// NewContentNegotiator creates a new ContentNegotiator feeding the given registry
+func NewContentNegotiator[T core.TypeCarrier](registry *TypeRegistry[T]) *ContentNegotiator[T] {
+	return &ContentNegotiator[T]{
+		registry: registry,
+		codecs:   map[string]Codec{},
+	}
+}
+
+// WithCodec routes payloads of the given media type to the given codec
+func (negotiator *ContentNegotiator[T]) WithCodec(mediatype string, codec Codec) *ContentNegotiator[T] {
+	negotiator.codecs[mediatype] = codec
+	return negotiator
+}
+
+// Unmarshal unmarshals the given payload according to its content type.
+//
+// The content type can be a full header value ("application/msgpack;
+// charset=binary") or a bare MIME string. An empty content type,
+// application/json and +json suffixed types are decoded natively; other
+// types must have a codec registered or an errors.UnsupportedMediaType is
+// returned.
+func (negotiator *ContentNegotiator[T]) Unmarshal(contentType string, payload []byte) (object T, err error) {
+	mediatype := ""
+	if contentType != "" {
+		if mediatype, _, err = mime.ParseMediaType(contentType); err != nil {
+			return object, errors.UnsupportedMediaType.With(contentType, negotiator.supportedTypes())
+		}
+	}
+	if mediatype == "" || mediatype == "application/json" || strings.HasSuffix(mediatype, "+json") {
+		return negotiator.registry.Unmarshal(payload)
+	}
+	codec, found := negotiator.codecs[mediatype]
+	if !found {
+		return object, errors.UnsupportedMediaType.With(mediatype, negotiator.supportedTypes())
+	}
+	return negotiator.registry.unmarshalWithCodec(context.Background(), codec, payload)
+}
+
+// FromRequest decodes the body of an HTTP request according to its Content-Type header
+func (negotiator *ContentNegotiator[T]) FromRequest(r *http.Request) (object T, err error) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return negotiator.Unmarshal(r.Header.Get("Content-Type"), payload)
+}
+
+// supportedTypes gives the sorted media types the negotiator accepts
+func (negotiator *ContentNegotiator[T]) supportedTypes() []string {
+	mediatypes := []string{"application/json"}
+	for mediatype := range negotiator.codecs {
+		mediatypes = append(mediatypes, mediatype)
+	}
+	sort.Strings(mediatypes)
+	return mediatypes
+}
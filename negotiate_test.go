@@ -0,0 +1,77 @@
+package argo_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/codecs/msgpack"
+	"github.com/gildas/argo/errors"
+)
+
+func TestNegotiatorDecodesJSONNatively(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	negotiator := argo.NewContentNegotiator(registry)
+
+	payload := []byte(`{"type": "something1", "text": "hello"}`)
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8", "application/vnd.example+json"} {
+		object, err := negotiator.Unmarshal(contentType, payload)
+		if err != nil {
+			t.Fatalf("failed to unmarshal %q: %s", contentType, err)
+		}
+		if _, ok := object.(*Something1); !ok {
+			t.Errorf("expected a *Something1, got %T", object)
+		}
+	}
+}
+
+func TestNegotiatorSelectsCodecByMediaType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	negotiator := argo.NewContentNegotiator(registry).WithCodec("application/msgpack", msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"type": "something1", "text": "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	object, err := negotiator.Unmarshal("application/msgpack", payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestNegotiatorRejectsUnknownMediaTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	negotiator := argo.NewContentNegotiator(registry)
+
+	if _, err := negotiator.Unmarshal("application/msgpack", []byte(`{}`)); !errors.Is(err, errors.UnsupportedMediaType) {
+		t.Errorf("error should be an UnsupportedMediaType, got %s", err)
+	}
+}
+
+func TestNegotiatorDecodesRequests(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	negotiator := argo.NewContentNegotiator(registry).WithCodec("application/msgpack", msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"type": "something1", "text": "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	r := httptest.NewRequest("POST", "/things", bytes.NewReader(payload))
+	r.Header.Set("Content-Type", "application/msgpack")
+
+	object, err := negotiator.FromRequest(r)
+	if err != nil {
+		t.Fatalf("failed to decode request: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
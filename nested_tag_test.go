@@ -0,0 +1,56 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestNestedTagInDotNotation(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("metadata.kind").Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"metadata": {"kind": "something1"}, "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestNestedTagAsJSONPointer(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("/metadata/kind").Add(Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"metadata": {"kind": "something2"}, "value": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something2 := object.(*Something2); something2.Value != 7 {
+		t.Errorf("expected 7, got %d", something2.Value)
+	}
+}
+
+func TestLiteralKeyWinsOverPathInterpretation(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("metadata.kind").Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"metadata.kind": "something1", "text": "hi"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestToMapInjectsNestedTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("metadata.kind").Add(Something1{})
+
+	data, err := registry.ToMap(Something1{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	metadata, ok := data["metadata"].(map[string]any)
+	if !ok || metadata["kind"] != "something1" {
+		t.Errorf("expected a nested type tag, got %v", data)
+	}
+}
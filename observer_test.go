@@ -0,0 +1,46 @@
+package argo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+)
+
+// recordingObserver records decode notifications for assertions
+type recordingObserver struct {
+	typenames []string
+	failures  int
+}
+
+func (observer *recordingObserver) ObserveUnmarshal(typename string, duration time.Duration, err error) {
+	observer.typenames = append(observer.typenames, typename)
+	if err != nil {
+		observer.failures++
+	}
+}
+
+func TestObserverSeesEveryDecode(t *testing.T) {
+	observer := &recordingObserver{}
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithObserver(observer)
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "bogus"}`)); err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+
+	if len(observer.typenames) != 2 {
+		t.Fatalf("expected 2 observations, got %d", len(observer.typenames))
+	}
+	if observer.typenames[0] != "something1" {
+		t.Errorf(`expected "something1", got %q`, observer.typenames[0])
+	}
+	if observer.typenames[1] != "" {
+		t.Errorf("expected an empty type name for the failure, got %q", observer.typenames[1])
+	}
+	if observer.failures != 1 {
+		t.Errorf("expected 1 failure, got %d", observer.failures)
+	}
+}
@@ -0,0 +1,84 @@
+package argo
+
+import (
+	"log/slog"
+	"reflect"
+
+	"github.com/gildas/argo/core"
+)
+
+// Option configures a registry at construction time.
+//
+// Options give NewTypeRegistryWith one coherent place to grow behavior
+// without breaking constructor signatures.
+type Option func(*registryOptions)
+
+// registryOptions collects what the options configure
+type registryOptions struct {
+	tags            []string
+	strict          bool
+	useNumber       bool
+	caseInsensitive bool
+	defaultClass    reflect.Type
+	logger          *slog.Logger
+}
+
+// WithTypeTags sets the JSON keys carrying the discriminator, tried in order
+func WithTypeTags(tags ...string) Option {
+	return func(options *registryOptions) {
+		options.tags = tags
+	}
+}
+
+// WithStrict rejects payloads carrying fields not defined on the matched
+// struct (see Strict)
+func WithStrict() Option {
+	return func(options *registryOptions) {
+		options.strict = true
+	}
+}
+
+// WithUseNumber decodes numbers into json.Number (see UseNumber)
+func WithUseNumber() Option {
+	return func(options *registryOptions) {
+		options.useNumber = true
+	}
+}
+
+// WithCaseInsensitive matches discriminators regardless of case, so "Message"
+// and "message" resolve to the same registered type
+func WithCaseInsensitive() Option {
+	return func(options *registryOptions) {
+		options.caseInsensitive = true
+	}
+}
+
+// WithDefaultType decodes payloads with an unknown discriminator into the
+// given type (see WithDefaultType on TypeRegistry)
+func WithDefaultType(class core.TypeCarrier) Option {
+	return func(options *registryOptions) {
+		options.defaultClass = dereferenceType(reflect.TypeOf(class))
+	}
+}
+
+// WithLogger makes the registry log registrations and decode failures
+func WithLogger(logger *slog.Logger) Option {
+	return func(options *registryOptions) {
+		options.logger = logger
+	}
+}
+
+// NewTypeRegistryWith creates a new TypeRegistry configured by options
+func NewTypeRegistryWith[T core.TypeCarrier](options ...Option) *TypeRegistry[T] {
+	var config registryOptions
+	for _, option := range options {
+		option(&config)
+	}
+	registry := NewTypeRegistry[T](config.tags...)
+	registry.strict = config.strict
+	registry.useNumber = config.useNumber
+	registry.caseInsensitive = config.caseInsensitive
+	registry.defaultClass = config.defaultClass
+	registry.logger = config.logger
+	return registry
+}
@@ -0,0 +1,45 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestOptionsConfigureTheRegistry(t *testing.T) {
+	registry := argo.NewTypeRegistryWith[Something](
+		argo.WithTypeTags("kind", "type"),
+		argo.WithStrict(),
+	).Add(Something2{})
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2", "bogus": 1}`)); err == nil {
+		t.Error("strict mode should have rejected the payload")
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 7}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestOptionsCaseInsensitiveDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistryWith[Something](argo.WithCaseInsensitive()).Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "SomeThing1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestOptionsDefaultType(t *testing.T) {
+	registry := argo.NewTypeRegistryWith[Something](argo.WithDefaultType(Legacy{})).Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "brand-new", "code": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Legacy); !ok {
+		t.Errorf("expected a *Legacy, got %T", object)
+	}
+}
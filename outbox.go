@@ -0,0 +1,88 @@
+package argo
+
+import (
+	"encoding/json"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// OutboxRow is the canonical representation of an object in a transactional
+// outbox table.
+//
+// Services write rows in the same transaction as their business data; a
+// relay reads them back and publishes them. ToOutbox and OutboxScanner
+// standardize both sides for registered types.
+type OutboxRow struct {
+	// Type is the discriminator of the payload
+	Type string `json:"type"`
+	// Payload is the canonical serialization of the object (sorted keys)
+	Payload []byte `json:"payload"`
+	// Headers carries transport metadata (correlation IDs, tenant, ...)
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ToOutbox marshals the given object into an outbox row.
+//
+// The payload is serialized canonically (type tag injected, keys sorted), so
+// two writes of the same object produce the same bytes.
+func (registry *TypeRegistry[T]) ToOutbox(object T, headers map[string]string) (OutboxRow, error) {
+	data, err := registry.ToMap(object)
+	if err != nil {
+		return OutboxRow{}, err
+	}
+	payload, err := json.Marshal(data) // map keys are marshaled in sorted order
+	if err != nil {
+		return OutboxRow{}, errors.JSONMarshalError.Wrap(err)
+	}
+	return OutboxRow{Type: object.GetType(), Payload: payload, Headers: headers}, nil
+}
+
+// FromOutbox decodes an outbox row back into its object.
+//
+// The row's Type column must agree with the payload's discriminator.
+func (registry *TypeRegistry[T]) FromOutbox(row OutboxRow) (object T, err error) {
+	object, err = registry.Unmarshal(row.Payload)
+	if err != nil {
+		return object, err
+	}
+	if object.GetType() != row.Type {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("type", row.Type))
+	}
+	return object, nil
+}
+
+// OutboxScanner decodes a batch of outbox rows one by one, relay side
+type OutboxScanner[T core.TypeCarrier] struct {
+	registry *TypeRegistry[T]
+	rows     []OutboxRow
+	index    int
+	object   T
+	err      error
+}
+
+// ScanOutbox returns a scanner over the given rows
+func (registry *TypeRegistry[T]) ScanOutbox(rows []OutboxRow) *OutboxScanner[T] {
+	return &OutboxScanner[T]{registry: registry, rows: rows}
+}
+
+// Next advances to the next row, reporting false when the rows are exhausted
+// or a row failed to decode (see Err)
+func (scanner *OutboxScanner[T]) Next() bool {
+	if scanner.err != nil || scanner.index >= len(scanner.rows) {
+		return false
+	}
+	scanner.object, scanner.err = scanner.registry.FromOutbox(scanner.rows[scanner.index])
+	scanner.index++
+	return scanner.err == nil
+}
+
+// Object gives the object decoded by the last successful Next
+func (scanner *OutboxScanner[T]) Object() T {
+	return scanner.object
+}
+
+// Err gives the first decoding failure, if any
+func (scanner *OutboxScanner[T]) Err() error {
+	return scanner.err
+}
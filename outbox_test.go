@@ -0,0 +1,75 @@
+package argo_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestOutboxRoundTrip(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	row, err := registry.ToOutbox(Something1{Text: "hello"}, map[string]string{"tenant": "acme"})
+	if err != nil {
+		t.Fatalf("failed to write outbox row: %s", err)
+	}
+	if row.Type != "something1" {
+		t.Errorf(`expected type "something1", got %q`, row.Type)
+	}
+
+	object, err := registry.FromOutbox(row)
+	if err != nil {
+		t.Fatalf("failed to read outbox row: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestOutboxSerializationIsCanonical(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	first, err := registry.ToOutbox(Something1{Text: "hello"}, nil)
+	if err != nil {
+		t.Fatalf("failed to write outbox row: %s", err)
+	}
+	second, _ := registry.ToOutbox(Something1{Text: "hello"}, nil)
+	if !bytes.Equal(first.Payload, second.Payload) {
+		t.Errorf("payloads should be identical:\n%s\n%s", first.Payload, second.Payload)
+	}
+}
+
+func TestOutboxRejectsInconsistentTypeColumn(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	row, err := registry.ToOutbox(Something1{Text: "hello"}, nil)
+	if err != nil {
+		t.Fatalf("failed to write outbox row: %s", err)
+	}
+	row.Type = "something2"
+	if _, err := registry.FromOutbox(row); err == nil {
+		t.Error("should have failed to read outbox row")
+	}
+}
+
+func TestOutboxScannerDecodesAllRows(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	rows := []argo.OutboxRow{}
+	row, _ := registry.ToOutbox(Something1{Text: "hello"}, nil)
+	rows = append(rows, row)
+	row, _ = registry.ToOutbox(Something2{Value: 7}, nil)
+	rows = append(rows, row)
+
+	var objects []Something
+	scanner := registry.ScanOutbox(rows)
+	for scanner.Next() {
+		objects = append(objects, scanner.Object())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scanner failed: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("expected 2 objects, got %d", len(objects))
+	}
+}
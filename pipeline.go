@@ -0,0 +1,195 @@
+package argo
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/argo/core"
+)
+
+// Stage processes an object flowing through a Pipeline.
+//
+// A stage can transform the object, drop it (by returning false), or abort
+// the pipeline (by returning an error).
+type Stage[T core.TypeCarrier] func(context.Context, T) (T, bool, error)
+
+// Sink receives the objects that made it through all the stages of a Pipeline
+type Sink[T core.TypeCarrier] func(context.Context, T) error
+
+// PipelineRun is a running Pipeline
+type PipelineRun[T core.TypeCarrier] struct {
+	cancel     context.CancelFunc
+	stopIntake chan struct{}
+	stopOnce   sync.Once
+	group      sync.WaitGroup
+	failure    sync.Once
+	err        error
+	decoded    atomic.Int64
+	delivered  atomic.Int64
+	filtered   atomic.Int64
+	dropped    atomic.Int64
+}
+
+// PipelineStats reports what happened to the messages of a Pipeline
+type PipelineStats struct {
+	// Decoded counts the payloads successfully decoded
+	Decoded int64
+	// Delivered counts the objects that reached the sink
+	Delivered int64
+	// Filtered counts the objects dropped by a stage
+	Filtered int64
+	// Dropped counts the in-flight objects discarded by a hard shutdown
+	Dropped int64
+}
+
+// PipelineOptions tunes the behavior of a Pipeline
+type PipelineOptions struct {
+	// Quarantine, when set, keeps decode failures from aborting the pipeline
+	// and dead-letters poison messages (see QuarantinePolicy)
+	Quarantine *QuarantinePolicy
+}
+
+// pipelineBuffer bounds the channel between the decode and the processing stages
+const pipelineBuffer = 16
+
+// Pipeline chains decoding, stages and a sink behind bounded channels.
+//
+// Payloads read from the source are decoded through the registry, flow
+// through the stages in order, and end in the sink. The pipeline stops when
+// the source is closed, the context is canceled, or a stage, the sink or the
+// decoder fails. Wait reports the outcome, Close shuts the pipeline down
+// gracefully.
+func Pipeline[T core.TypeCarrier](ctx context.Context, source <-chan []byte, registry *TypeRegistry[T], sink Sink[T], stages ...Stage[T]) *PipelineRun[T] {
+	return PipelineWithOptions(ctx, source, registry, PipelineOptions{}, sink, stages...)
+}
+
+// PipelineWithOptions is a Pipeline tuned with the given options
+func PipelineWithOptions[T core.TypeCarrier](ctx context.Context, source <-chan []byte, registry *TypeRegistry[T], options PipelineOptions, sink Sink[T], stages ...Stage[T]) *PipelineRun[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	run := &PipelineRun[T]{cancel: cancel, stopIntake: make(chan struct{})}
+	decoded := make(chan T, pipelineBuffer)
+
+	run.group.Add(2)
+	go func() { // decode
+		defer run.group.Done()
+		defer close(decoded)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-run.stopIntake:
+				return
+			case payload, opened := <-source:
+				if !opened {
+					return
+				}
+				object, err := registry.Unmarshal(payload)
+				if err != nil {
+					if options.Quarantine == nil {
+						run.abort(err)
+						return
+					}
+					options.Quarantine.record(payload, err)
+					continue
+				}
+				if options.Quarantine != nil {
+					options.Quarantine.forget(payload)
+				}
+				run.decoded.Add(1)
+				select {
+				case <-ctx.Done():
+					run.dropped.Add(1)
+				case decoded <- object:
+				}
+			}
+		}
+	}()
+	go func() { // process and dispatch
+		defer run.group.Done()
+		defer func() { // whatever never reached the sink is dropped
+			for range decoded {
+				run.dropped.Add(1)
+			}
+		}()
+		for object := range decoded {
+			select {
+			case <-ctx.Done(): // hard shutdown: drain the buffer, counting drops
+				run.dropped.Add(1)
+				continue
+			default:
+			}
+			keep := true
+			var err error
+			for _, stage := range stages {
+				if object, keep, err = stage(ctx, object); err != nil {
+					run.abort(err)
+					return
+				} else if !keep {
+					run.filtered.Add(1)
+					break
+				}
+			}
+			if keep {
+				if err := sink(ctx, object); err != nil {
+					run.abort(err)
+					return
+				}
+				run.delivered.Add(1)
+			}
+		}
+	}()
+	return run
+}
+
+// Wait blocks until the pipeline stops and reports its outcome
+func (run *PipelineRun[T]) Wait() error {
+	run.group.Wait()
+	run.cancel()
+	return run.err
+}
+
+// Close shuts the pipeline down gracefully.
+//
+// Intake stops immediately, in-flight objects are drained within the given
+// context's deadline, then the pipeline is stopped hard and whatever is left
+// is discarded and reported as dropped in Stats. Stages and sinks must honor
+// the cancellation of the context they receive for the hard stop to complete.
+func (run *PipelineRun[T]) Close(ctx context.Context) error {
+	run.stopOnce.Do(func() { close(run.stopIntake) })
+	done := make(chan struct{})
+	go func() {
+		run.group.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		run.cancel()
+		return run.err
+	case <-ctx.Done():
+		run.cancel()
+		<-done
+		if run.err != nil {
+			return run.err
+		}
+		return ctx.Err()
+	}
+}
+
+// Stats reports what happened to the messages seen so far
+func (run *PipelineRun[T]) Stats() PipelineStats {
+	return PipelineStats{
+		Decoded:   run.decoded.Load(),
+		Delivered: run.delivered.Load(),
+		Filtered:  run.filtered.Load(),
+		Dropped:   run.dropped.Load(),
+	}
+}
+
+// abort records the first failure and stops the pipeline
+func (run *PipelineRun[T]) abort(err error) {
+	run.failure.Do(func() {
+		run.err = err
+		run.cancel()
+	})
+}
@@ -0,0 +1,119 @@
+package argo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+)
+
+func TestPipelineDecodesTransformsAndDispatches(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	source := make(chan []byte, 3)
+	source <- []byte(`{"type": "something1", "text": "hello"}`)
+	source <- []byte(`{"type": "something2", "value": 7}`)
+	source <- []byte(`{"type": "something1", "text": "drop me"}`)
+	close(source)
+
+	var received []Something
+	run := argo.Pipeline(context.Background(), source, registry,
+		func(ctx context.Context, object Something) error {
+			received = append(received, object)
+			return nil
+		},
+		func(ctx context.Context, object Something) (Something, bool, error) {
+			if something1, ok := object.(*Something1); ok && strings.HasPrefix(something1.Text, "drop") {
+				return object, false, nil
+			}
+			return object, true, nil
+		},
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("expected 2 objects, got %d", len(received))
+	}
+}
+
+func TestPipelineStopsOnDecodeError(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	source := make(chan []byte, 1)
+	source <- []byte(`{"type": "something42"}`)
+	close(source)
+
+	run := argo.Pipeline(context.Background(), source, registry,
+		func(ctx context.Context, object Something) error { return nil },
+	)
+	if err := run.Wait(); err == nil {
+		t.Error("pipeline should have failed")
+	}
+}
+
+func TestPipelineHonorsCancellation(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	source := make(chan []byte) // never closed
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := argo.Pipeline(ctx, source, registry,
+		func(ctx context.Context, object Something) error { return nil },
+	)
+	cancel()
+	if err := run.Wait(); err != nil {
+		t.Errorf("cancellation should not be an error, got %s", err)
+	}
+}
+
+func TestPipelineCloseDrainsInFlightWork(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	source := make(chan []byte, 4)
+	for i := 0; i < 4; i++ {
+		source <- []byte(`{"type": "something1", "text": "hello"}`)
+	}
+
+	var delivered int
+	run := argo.Pipeline(context.Background(), source, registry,
+		func(ctx context.Context, object Something) error {
+			delivered++
+			return nil
+		},
+	)
+	time.Sleep(50 * time.Millisecond)
+	if err := run.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close: %s", err)
+	}
+	stats := run.Stats()
+	if stats.Delivered != 4 {
+		t.Errorf("expected 4 delivered, got %+v", stats)
+	}
+	if stats.Dropped != 0 {
+		t.Errorf("expected no drops, got %+v", stats)
+	}
+}
+
+func TestPipelineCloseReportsDropsOnDeadline(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	source := make(chan []byte, 4)
+	for i := 0; i < 4; i++ {
+		source <- []byte(`{"type": "something1", "text": "hello"}`)
+	}
+
+	run := argo.Pipeline(context.Background(), source, registry,
+		func(ctx context.Context, object Something) error {
+			<-ctx.Done() // the sink never makes progress on its own
+			return ctx.Err()
+		},
+	)
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := run.Close(ctx)
+	if err == nil {
+		t.Error("close should have reported the deadline")
+	}
+	if stats := run.Stats(); stats.Dropped == 0 {
+		t.Errorf("expected drops, got %+v", stats)
+	}
+}
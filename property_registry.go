@@ -0,0 +1,420 @@
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// PropertyRegistry unmarshals JSON payloads into Go types selected by a property key.
+//
+// Where TypeRegistry reads a discriminator value, PropertyRegistry matches the
+// presence of a property named after the registered type and decodes that
+// property's value:
+//
+//	{"something1": {"text": "hello"}}
+//
+// is decoded into the type registered under the name "something1".
+//
+// Tags given to NewPropertyRegistry scope the match: properties are then
+// looked up inside those top-level keys first, so envelope shapes like
+// {"payload": {"something1": {...}}} resolve without unwrapping.
+//
+// Registered names may be dotted paths ("payload.message"), in which case
+// the dispatching property is looked up inside the named wrapper objects.
+type PropertyRegistry[T core.TypeCarrier] struct {
+	tags            []string
+	order           []string
+	priorities      map[string]int
+	classes         map[string]reflect.Type
+	patterns        []propertyPattern
+	frozen          bool
+	failOnAmbiguity bool
+	codec           Codec
+	backend         JSONBackend
+}
+
+// NewPropertyRegistry creates a new PropertyRegistry.
+//
+// The given tags are top-level keys to descend into before matching
+// properties, tried in order; the top level itself is matched last. Without
+// tags, properties are matched at the top level only.
+func NewPropertyRegistry[T core.TypeCarrier](tags ...string) *PropertyRegistry[T] {
+	return &PropertyRegistry[T]{
+		tags:       tags,
+		priorities: map[string]int{},
+		classes:    map[string]reflect.Type{},
+	}
+}
+
+// Add adds one or more types to the registry, keyed by their type name.
+//
+// Adding to a frozen registry panics with errors.RegistryFrozen (see Freeze).
+func (registry *PropertyRegistry[T]) Add(classes ...T) *PropertyRegistry[T] {
+	return registry.AddWithPriority(0, classes...)
+}
+
+// AddWithPriority adds one or more types to the registry with the given priority.
+//
+// When several registered properties appear in the same payload, the one with
+// the highest priority is decoded; properties of equal priority are tried in
+// registration order. Add registers with priority 0.
+func (registry *PropertyRegistry[T]) AddWithPriority(priority int, classes ...T) *PropertyRegistry[T] {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	for _, class := range classes {
+		name := class.GetType()
+		if _, found := registry.classes[name]; !found {
+			registry.order = append(registry.order, name)
+		}
+		registry.classes[name] = dereferenceType(reflect.TypeOf(class))
+		registry.priorities[name] = priority
+	}
+	sort.SliceStable(registry.order, func(i, j int) bool {
+		return registry.priorities[registry.order[i]] > registry.priorities[registry.order[j]]
+	})
+	return registry
+}
+
+// AddWithProperty adds a type to the registry under the given property key
+// instead of its type name, for wire formats whose keys differ from the
+// logical type names.
+func (registry *PropertyRegistry[T]) AddWithProperty(property string, class T) *PropertyRegistry[T] {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	if _, found := registry.classes[property]; !found {
+		registry.order = append(registry.order, property)
+	}
+	registry.classes[property] = dereferenceType(reflect.TypeOf(class))
+	registry.priorities[property] = 0
+	sort.SliceStable(registry.order, func(i, j int) bool {
+		return registry.priorities[registry.order[i]] > registry.priorities[registry.order[j]]
+	})
+	return registry
+}
+
+// Remove removes a type from the registry.
+//
+// Removing a type that is not registered is a no-op.
+func (registry *PropertyRegistry[T]) Remove(typename string) {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	delete(registry.classes, typename)
+	delete(registry.priorities, typename)
+	for index, name := range registry.order {
+		if name == typename {
+			registry.order = append(registry.order[:index], registry.order[index+1:]...)
+			break
+		}
+	}
+}
+
+// propertyPattern maps property keys matching a wildcard or a regexp to a type
+type propertyPattern struct {
+	wildcard string
+	regex    *regexp.Regexp
+	class    reflect.Type
+}
+
+// matches tells if the given property key matches the pattern
+func (pattern propertyPattern) matches(key string) bool {
+	if pattern.regex != nil {
+		return pattern.regex.MatchString(key)
+	}
+	matched, _ := path.Match(pattern.wildcard, key)
+	return matched
+}
+
+// PropertySetter is implemented by types that want to know which property
+// key selected them when they were registered under a pattern.
+type PropertySetter interface {
+	SetProperty(key string)
+}
+
+// AddPattern maps property keys matching the given wildcard pattern
+// ("event:*", shell-style, see path.Match) to the given type.
+//
+// Patterns are tried after exact property names, in registration order; when
+// several keys of the payload match, the lexicographically smallest wins. If
+// the decoded object implements PropertySetter, it receives the matched key.
+func (registry *PropertyRegistry[T]) AddPattern(pattern string, class T) *PropertyRegistry[T] {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	registry.patterns = append(registry.patterns, propertyPattern{wildcard: pattern, class: dereferenceType(reflect.TypeOf(class))})
+	return registry
+}
+
+// AddRegexp maps property keys matching the given regular expression to the
+// given type (see AddPattern).
+func (registry *PropertyRegistry[T]) AddRegexp(pattern *regexp.Regexp, class T) *PropertyRegistry[T] {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	registry.patterns = append(registry.patterns, propertyPattern{regex: pattern, class: dereferenceType(reflect.TypeOf(class))})
+	return registry
+}
+
+// FailOnAmbiguity makes Unmarshal reject payloads carrying several
+// registered property keys at the same level instead of decoding whichever
+// matches first.
+//
+// The error is an errors.ArgumentInvalid listing all matching properties,
+// wrapped in an errors.JSONUnmarshalError.
+func (registry *PropertyRegistry[T]) FailOnAmbiguity() *PropertyRegistry[T] {
+	registry.failOnAmbiguity = true
+	return registry
+}
+
+// Size gives the number of types registered
+func (registry *PropertyRegistry[T]) Size() int {
+	return len(registry.classes)
+}
+
+// Types gives the sorted registered type names
+func (registry *PropertyRegistry[T]) Types() []string {
+	types := make([]string, 0, len(registry.classes))
+	for typename := range registry.classes {
+		types = append(types, typename)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Unmarshal unmarshals the given payload into the type matching one of its properties.
+//
+// If no property matches a registered type, an errors.ArgumentMissing is
+// returned, wrapped in an errors.JSONUnmarshalError.
+func (registry *PropertyRegistry[T]) Unmarshal(payload []byte) (object T, err error) {
+	object, _, err = registry.UnmarshalWithKey(payload)
+	return object, err
+}
+
+// UnmarshalWithKey unmarshals the given payload like Unmarshal and also
+// gives the property key that matched, for logging and acknowledging
+// purposes.
+func (registry *PropertyRegistry[T]) UnmarshalWithKey(payload []byte) (object T, key string, err error) {
+	var guts map[string]json.RawMessage
+
+	if registry.codec != nil {
+		return registry.unmarshalWithCodec(payload)
+	}
+	if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+		return object, "", errors.JSONUnmarshalError.Wrap(err)
+	}
+	for _, scope := range registry.tags {
+		raw, found := guts[scope]
+		if !found {
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if err = registry.jsonUnmarshal(raw, &nested); err != nil {
+			continue
+		}
+		if object, key, err, matched := registry.match(nested); matched {
+			return object, key, err
+		}
+	}
+	if object, key, err, matched := registry.match(guts); matched {
+		return object, key, err
+	}
+	return object, "", errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("property"))
+}
+
+// CanUnmarshal tells whether the payload carries a property this registry
+// knows, for content sniffing across several registries.
+//
+// Only the property keys are inspected: no property value is decoded.
+func (registry *PropertyRegistry[T]) CanUnmarshal(payload []byte) bool {
+	var guts map[string]json.RawMessage
+
+	if registry.jsonUnmarshal(payload, &guts) != nil {
+		return false
+	}
+	levels := []map[string]json.RawMessage{}
+	for _, scope := range registry.tags {
+		raw, found := guts[scope]
+		if !found {
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if registry.jsonUnmarshal(raw, &nested) == nil {
+			levels = append(levels, nested)
+		}
+	}
+	levels = append(levels, guts)
+	for _, level := range levels {
+		for _, key := range registry.order {
+			if _, found := registry.lookup(level, key); found {
+				return true
+			}
+		}
+		for _, pattern := range registry.patterns {
+			for key := range level {
+				if pattern.matches(key) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// UnmarshalAll unmarshals every registered property present in the payload.
+//
+// Properties are decoded in registration order (then pattern order), like
+// Unmarshal picks its first match, so multi-object payloads such as
+// {"something1": {...}, "something2": {...}} come back in a deterministic
+// order. Properties that decode successfully are always returned; when some
+// fail, the error is a *BatchError recording the property key and cause of
+// each failure. If no property matches at all, an errors.ArgumentMissing is
+// returned, wrapped in an errors.JSONUnmarshalError.
+func (registry *PropertyRegistry[T]) UnmarshalAll(payload []byte) (objects []T, err error) {
+	var guts map[string]json.RawMessage
+
+	if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	for _, scope := range registry.tags {
+		raw, found := guts[scope]
+		if !found {
+			continue
+		}
+		var nested map[string]json.RawMessage
+		if err = registry.jsonUnmarshal(raw, &nested); err != nil {
+			continue
+		}
+		if objects, err, matched := registry.matchAll(nested); matched {
+			return objects, err
+		}
+	}
+	if objects, err, matched := registry.matchAll(guts); matched {
+		return objects, err
+	}
+	return nil, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("property"))
+}
+
+// matchAll decodes every property matching a registered type, reporting
+// whether any matched at all
+func (registry *PropertyRegistry[T]) matchAll(guts map[string]json.RawMessage) (objects []T, err error, matched bool) {
+	batch := &BatchError{}
+	decode := func(raw json.RawMessage, key string, class reflect.Type) {
+		value := reflect.New(class).Interface()
+		if err := registry.jsonUnmarshal(raw, value); err != nil {
+			batch.Failures = append(batch.Failures, BatchFailure{Index: len(objects) + len(batch.Failures), Discriminator: key, Cause: errors.JSONUnmarshalError.Wrap(err)})
+			return
+		}
+		if setter, ok := value.(PropertySetter); ok {
+			setter.SetProperty(key)
+		}
+		objects = append(objects, asRegistered[T](value))
+	}
+	for _, key := range registry.order {
+		if raw, found := registry.lookup(guts, key); found {
+			decode(raw, key, registry.classes[key])
+		}
+	}
+	if len(registry.patterns) > 0 {
+		keys := make([]string, 0, len(guts))
+		for key := range guts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, pattern := range registry.patterns {
+			for _, key := range keys {
+				if _, exact := registry.classes[key]; exact {
+					continue
+				}
+				if pattern.matches(key) {
+					decode(guts[key], key, pattern.class)
+				}
+			}
+		}
+	}
+	if len(batch.Failures) > 0 {
+		return objects, batch, true
+	}
+	return objects, nil, len(objects) > 0
+}
+
+// match decodes the first property matching a registered type, reporting
+// the matched key and whether one matched at all
+func (registry *PropertyRegistry[T]) match(guts map[string]json.RawMessage) (object T, key string, err error, matched bool) {
+	if registry.failOnAmbiguity {
+		matches := []string{}
+		for _, key := range registry.order {
+			if _, found := registry.lookup(guts, key); found {
+				matches = append(matches, key)
+			}
+		}
+		if len(matches) > 1 {
+			sort.Strings(matches)
+			return object, "", errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("properties", fmt.Sprintf("%v", matches))), true
+		}
+	}
+	for _, key := range registry.order {
+		raw, found := registry.lookup(guts, key)
+		if !found {
+			continue
+		}
+		value := reflect.New(registry.classes[key]).Interface()
+		if err = registry.jsonUnmarshal(raw, value); err != nil {
+			return object, key, errors.JSONUnmarshalError.Wrap(err), true
+		}
+		return asRegistered[T](value), key, nil, true
+	}
+	if len(registry.patterns) > 0 {
+		keys := make([]string, 0, len(guts))
+		for key := range guts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, pattern := range registry.patterns {
+			for _, key := range keys {
+				if !pattern.matches(key) {
+					continue
+				}
+				value := reflect.New(pattern.class).Interface()
+				if err = registry.jsonUnmarshal(guts[key], value); err != nil {
+					return object, key, errors.JSONUnmarshalError.Wrap(err), true
+				}
+				if setter, ok := value.(PropertySetter); ok {
+					setter.SetProperty(key)
+				}
+				return asRegistered[T](value), key, nil, true
+			}
+		}
+	}
+	return object, "", nil, false
+}
+
+// lookup finds the value of the given property, descending into nested
+// objects when the property is a dotted path ("payload.message")
+func (registry *PropertyRegistry[T]) lookup(guts map[string]json.RawMessage, key string) (json.RawMessage, bool) {
+	parts := strings.Split(key, ".")
+	for index, part := range parts {
+		raw, found := guts[part]
+		if !found {
+			return nil, false
+		}
+		if index == len(parts)-1 {
+			return raw, true
+		}
+		var nested map[string]json.RawMessage
+		if err := registry.jsonUnmarshal(raw, &nested); err != nil {
+			return nil, false
+		}
+		guts = nested
+	}
+	return nil, false
+}
@@ -0,0 +1,319 @@
+package argo_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestPropertyRegistryCanRegisterTypes(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{})
+	if size := registry.Size(); size != 2 {
+		t.Errorf("expected 2 types, got %d", size)
+	}
+}
+
+func TestPropertyRegistryCanUnmarshal(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestPropertyRegistryCanUnmarshalInsideScopes(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]("payload").Add(Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"payload": {"something1": {"text": "hello"}}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+
+	// the top level is still matched when no scope carries a property
+	if _, err = registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestSyncPropertyRegistryHonorsScopes(t *testing.T) {
+	registry := argo.NewSyncPropertyRegistry[Something]("payload").Add(Something1{})
+
+	if _, err := registry.Unmarshal([]byte(`{"payload": {"something1": {"text": "hello"}}}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestPropertyRegistryShouldFailWithoutMatchingProperty(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{})
+
+	_, err := registry.Unmarshal([]byte(`{"something42": {}}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestPropertyRegistryMatchesInRegistrationOrder(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something2{}, Something1{})
+
+	for i := 0; i < 20; i++ {
+		object, err := registry.Unmarshal([]byte(`{"something1": {"text": "hello"}, "something2": {"value": 7}}`))
+		if err != nil {
+			t.Fatalf("failed to unmarshal: %s", err)
+		}
+		if _, ok := object.(*Something2); !ok {
+			t.Fatalf("expected a *Something2 (registered first), got %T", object)
+		}
+	}
+}
+
+// deepThing dispatches off a property nested inside a wrapper object
+type deepThing struct {
+	Text string `json:"text"`
+}
+
+func (thing deepThing) GetType() string {
+	return "payload.message"
+}
+
+func TestPropertyRegistryCanMatchNestedPaths(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(deepThing{})
+
+	object, err := registry.Unmarshal([]byte(`{"payload": {"message": {"text": "hello"}}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	thing, ok := object.(*deepThing)
+	if !ok {
+		t.Fatalf("expected a *deepThing, got %T", object)
+	}
+	if thing.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, thing.Text)
+	}
+
+	if _, err = registry.Unmarshal([]byte(`{"payload": {"other": {}}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestPropertyRegistryHonorsPriorities(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().
+		Add(Something1{}).
+		AddWithPriority(10, Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"something1": {"text": "hello"}, "something2": {"value": 7}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something2); !ok {
+		t.Fatalf("expected a *Something2 (higher priority), got %T", object)
+	}
+
+	// the fallback representation still decodes on its own
+	object, err = registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+}
+
+// eventThing is selected by pattern and remembers which property key matched
+type eventThing struct {
+	Property string `json:"-"`
+	ID       string `json:"id"`
+}
+
+func (thing eventThing) GetType() string {
+	return "event"
+}
+
+func (thing *eventThing) SetProperty(key string) {
+	thing.Property = key
+}
+
+func TestPropertyRegistryCanMatchPatterns(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().AddPattern("event:*", eventThing{})
+
+	object, err := registry.Unmarshal([]byte(`{"event:created": {"id": "1234"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	thing, ok := object.(*eventThing)
+	if !ok {
+		t.Fatalf("expected a *eventThing, got %T", object)
+	}
+	if thing.Property != "event:created" {
+		t.Errorf(`expected property "event:created", got %q`, thing.Property)
+	}
+	if thing.ID != "1234" {
+		t.Errorf(`expected ID "1234", got %q`, thing.ID)
+	}
+}
+
+func TestPropertyRegistryCanMatchRegexps(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().
+		Add(Something1{}).
+		AddRegexp(regexp.MustCompile(`^event\.[a-z]+$`), eventThing{})
+
+	object, err := registry.Unmarshal([]byte(`{"event.updated": {"id": "1234"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	thing, ok := object.(*eventThing)
+	if !ok {
+		t.Fatalf("expected a *eventThing, got %T", object)
+	}
+	if thing.Property != "event.updated" {
+		t.Errorf(`expected property "event.updated", got %q`, thing.Property)
+	}
+
+	// exact property names win over patterns
+	object, err = registry.Unmarshal([]byte(`{"something1": {"text": "hello"}, "event.created": {}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestPropertyRegistryCanRegisterCustomProperties(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().AddWithProperty("msg", Something1{})
+
+	object, err := registry.Unmarshal([]byte(`{"msg": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+
+	// the type name itself is not registered
+	if _, err = registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestPropertyRegistryCanGiveMatchedKey(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{})
+
+	object, key, err := registry.UnmarshalWithKey([]byte(`{"something2": {"value": 7}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something2); !ok {
+		t.Fatalf("expected a *Something2, got %T", object)
+	}
+	if key != "something2" {
+		t.Errorf(`expected key "something2", got %q`, key)
+	}
+
+	if _, key, err = registry.UnmarshalWithKey([]byte(`{"something42": {}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if key != "" {
+		t.Errorf("expected no key, got %q", key)
+	}
+}
+
+func TestPropertyRegistryCanUnmarshalAll(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{})
+
+	objects, err := registry.UnmarshalAll([]byte(`{"something2": {"value": 7}, "something1": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if _, ok := objects[0].(*Something1); !ok {
+		t.Errorf("expected a *Something1 first (registration order), got %T", objects[0])
+	}
+	if _, ok := objects[1].(*Something2); !ok {
+		t.Errorf("expected a *Something2 second, got %T", objects[1])
+	}
+
+	if _, err = registry.UnmarshalAll([]byte(`{"something42": {}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestPropertyRegistryUnmarshalAllAggregatesFailures(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{})
+
+	objects, err := registry.UnmarshalAll([]byte(`{"something1": {"text": "hello"}, "something2": {"value": "seven"}}`))
+	if len(objects) != 1 {
+		t.Fatalf("expected 1 decoded object, got %d", len(objects))
+	}
+	var batch *argo.BatchError
+	if !errors.As(err, &batch) {
+		t.Fatalf("error should be a BatchError, got %T: %v", err, err)
+	}
+	if len(batch.Failures) != 1 || batch.Failures[0].Discriminator != "something2" {
+		t.Errorf("unexpected failures: %+v", batch.Failures)
+	}
+}
+
+func TestPropertyRegistryCanDetectAmbiguousPayloads(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().Add(Something1{}, Something2{}).FailOnAmbiguity()
+
+	_, err := registry.Unmarshal([]byte(`{"something1": {}, "something2": {}}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should be an ArgumentInvalid, got %s", err)
+	}
+	if !strings.Contains(err.Error(), "[something1 something2]") {
+		t.Errorf("error should list the matching properties, got %s", err)
+	}
+
+	// unambiguous payloads keep decoding
+	if _, err = registry.Unmarshal([]byte(`{"something1": {"text": "hello"}}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestPropertyRegistryCanUnmarshalSniffsPayloads(t *testing.T) {
+	registry := argo.NewPropertyRegistry[Something]().
+		Add(Something1{}).
+		AddPattern("event-*", Something2{})
+
+	if !registry.CanUnmarshal([]byte(`{"something1": {"text": "hello"}}`)) {
+		t.Error("a registered property should be sniffable")
+	}
+	if !registry.CanUnmarshal([]byte(`{"event-started": {"value": 2}}`)) {
+		t.Error("a pattern match should be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"bogus": {}}`)) {
+		t.Error("an unknown property should not be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"broken"`)) {
+		t.Error("a malformed payload should not be sniffable")
+	}
+}
@@ -0,0 +1,66 @@
+package argo
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// QuarantinePolicy quarantines payloads that keep failing to decode.
+//
+// Message queues redeliver failed messages; without a quarantine a payload
+// that can never be decoded (a poison message) loops forever. The policy
+// counts consecutive failures per message identity and, once the limit is
+// reached, hands the payload to the dead-letter callback and forgets it.
+type QuarantinePolicy struct {
+	// MaxFailures quarantines a payload after this many failures (default: 3)
+	MaxFailures int
+	// Identify gives the identity of a payload (default: a hash of its bytes)
+	Identify func(payload []byte) string
+	// DeadLetter receives quarantined payloads along with their last error
+	DeadLetter func(payload []byte, err error)
+
+	failures map[string]int
+}
+
+// quarantineDefaultMaxFailures is used when MaxFailures is not set
+const quarantineDefaultMaxFailures = 3
+
+// record notes a failure for the given payload and tells whether it should
+// now be quarantined
+func (policy *QuarantinePolicy) record(payload []byte, err error) {
+	if policy.failures == nil {
+		policy.failures = map[string]int{}
+	}
+	id := policy.identify(payload)
+	policy.failures[id]++
+	if policy.failures[id] >= policy.maxFailures() {
+		delete(policy.failures, id)
+		if policy.DeadLetter != nil {
+			policy.DeadLetter(payload, err)
+		}
+	}
+}
+
+// forget clears the failure history of the given payload, e.g. after it
+// finally decoded
+func (policy *QuarantinePolicy) forget(payload []byte) {
+	if policy.failures != nil {
+		delete(policy.failures, policy.identify(payload))
+	}
+}
+
+func (policy *QuarantinePolicy) identify(payload []byte) string {
+	if policy.Identify != nil {
+		return policy.Identify(payload)
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write(payload)
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+func (policy *QuarantinePolicy) maxFailures() int {
+	if policy.MaxFailures > 0 {
+		return policy.MaxFailures
+	}
+	return quarantineDefaultMaxFailures
+}
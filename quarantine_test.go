@@ -0,0 +1,69 @@
+package argo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestQuarantineDeadLettersPoisonMessages(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	poison := []byte(`{"type": "poison"}`)
+	source := make(chan []byte, 4)
+	source <- poison
+	source <- poison
+	source <- poison
+	source <- []byte(`{"type": "something1", "text": "hello"}`)
+	close(source)
+
+	var deadLettered [][]byte
+	options := argo.PipelineOptions{
+		Quarantine: &argo.QuarantinePolicy{
+			MaxFailures: 3,
+			DeadLetter:  func(payload []byte, err error) { deadLettered = append(deadLettered, payload) },
+		},
+	}
+	var delivered int
+	run := argo.PipelineWithOptions(context.Background(), source, registry, options,
+		func(ctx context.Context, object Something) error {
+			delivered++
+			return nil
+		},
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if len(deadLettered) != 1 {
+		t.Errorf("expected 1 dead-lettered payload, got %d", len(deadLettered))
+	}
+	if delivered != 1 {
+		t.Errorf("expected 1 delivered object, got %d", delivered)
+	}
+}
+
+func TestQuarantineForgivesTransientFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	poison := []byte(`{"type": "poison"}`)
+	source := make(chan []byte, 2)
+	source <- poison
+	source <- poison
+	close(source)
+
+	var deadLettered int
+	options := argo.PipelineOptions{
+		Quarantine: &argo.QuarantinePolicy{
+			MaxFailures: 3,
+			DeadLetter:  func(payload []byte, err error) { deadLettered++ },
+		},
+	}
+	run := argo.PipelineWithOptions(context.Background(), source, registry, options,
+		func(ctx context.Context, object Something) error { return nil },
+	)
+	if err := run.Wait(); err != nil {
+		t.Fatalf("pipeline failed: %s", err)
+	}
+	if deadLettered != 0 {
+		t.Errorf("expected no dead-lettered payload below the limit, got %d", deadLettered)
+	}
+}
@@ -0,0 +1,28 @@
+package argo
+
+import "github.com/gildas/argo/core"
+
+// Registry is the behavior common to all the registries in this module.
+//
+// Libraries can accept a Registry to work with any dispatch strategy
+// (discriminator, property, sharded, concurrency-safe or not) and tests can
+// run generically against every implementation. Registration stays on the
+// concrete types, as Add returns the concrete registry for chaining.
+type Registry[T core.TypeCarrier] interface {
+	// Unmarshal unmarshals the given payload into a registered type
+	Unmarshal(payload []byte) (T, error)
+	// Remove removes a type from the registry
+	Remove(typename string)
+	// Size gives the number of types registered
+	Size() int
+	// Types gives the registered type names
+	Types() []string
+}
+
+var (
+	_ Registry[core.TypeCarrier] = (*TypeRegistry[core.TypeCarrier])(nil)
+	_ Registry[core.TypeCarrier] = (*PropertyRegistry[core.TypeCarrier])(nil)
+	_ Registry[core.TypeCarrier] = (*SyncTypeRegistry[core.TypeCarrier])(nil)
+	_ Registry[core.TypeCarrier] = (*SyncPropertyRegistry[core.TypeCarrier])(nil)
+	_ Registry[core.TypeCarrier] = (*ShardedTypeRegistry[core.TypeCarrier])(nil)
+)
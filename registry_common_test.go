@@ -0,0 +1,35 @@
+package argo_test
+
+// Test fixtures shared by the registry tests.
+
+type Something interface {
+	GetType() string
+}
+
+type Something1 struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (something Something1) GetType() string {
+	return "something1"
+}
+
+// Legacy has no discriminator field of its own, like payloads whose type
+// tag is numeric or boolean on the wire
+type Legacy struct {
+	Code int `json:"code"`
+}
+
+func (legacy Legacy) GetType() string {
+	return "legacy"
+}
+
+type Something2 struct {
+	Type  string `json:"type"`
+	Value int    `json:"value"`
+}
+
+func (something Something2) GetType() string {
+	return "something2"
+}
@@ -0,0 +1,102 @@
+package argo
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// RegistryGroup maintains isolated type sets keyed by namespace.
+//
+// A multi-tenant gateway can give each customer a registry of their own
+// while sharing the type tags and options:
+//
+//	group := argo.NewRegistryGroup[Event]("type")
+//	group.Namespace("tenantA").Add(OrderCreated{})
+//	group.Namespace("tenantB").Add(LegacyOrder{})
+//	event, err := group.Unmarshal("tenantA", payload)
+//
+// Namespaces are created on first use; the group itself is safe for
+// concurrent use, the registries it hands out follow the TypeRegistry
+// rules (register first, decode concurrently after).
+type RegistryGroup[T core.TypeCarrier] struct {
+	lock       sync.RWMutex
+	tags       []string
+	configure  func(registry *TypeRegistry[T])
+	namespaces map[string]*TypeRegistry[T]
+}
+
+// NewRegistryGroup creates a new RegistryGroup whose namespaces share the
+// given type tags
+func NewRegistryGroup[T core.TypeCarrier](tags ...string) *RegistryGroup[T] {
+	return &RegistryGroup[T]{
+		tags:       tags,
+		namespaces: map[string]*TypeRegistry[T]{},
+	}
+}
+
+// Configure registers options applied to every namespace's registry when it
+// is created, e.g. Strict() or WithDefaultType; namespaces created before
+// the call are not revisited
+func (group *RegistryGroup[T]) Configure(configure func(registry *TypeRegistry[T])) *RegistryGroup[T] {
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	group.configure = configure
+	return group
+}
+
+// Namespace gives the registry for the given namespace, creating it on
+// first use
+func (group *RegistryGroup[T]) Namespace(name string) *TypeRegistry[T] {
+	group.lock.RLock()
+	registry, found := group.namespaces[name]
+	group.lock.RUnlock()
+	if found {
+		return registry
+	}
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	if registry, found = group.namespaces[name]; found {
+		return registry
+	}
+	registry = NewTypeRegistry[T](group.tags...)
+	if group.configure != nil {
+		group.configure(registry)
+	}
+	group.namespaces[name] = registry
+	return registry
+}
+
+// DropNamespace removes the given namespace and its registrations; dropping
+// an unknown namespace is a no-op
+func (group *RegistryGroup[T]) DropNamespace(name string) {
+	group.lock.Lock()
+	defer group.lock.Unlock()
+	delete(group.namespaces, name)
+}
+
+// Namespaces gives the sorted names of the existing namespaces
+func (group *RegistryGroup[T]) Namespaces() []string {
+	group.lock.RLock()
+	defer group.lock.RUnlock()
+	names := make([]string, 0, len(group.namespaces))
+	for name := range group.namespaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Unmarshal unmarshals the given payload through the given namespace's
+// registry; an unknown namespace fails with an errors.ArgumentInvalid
+func (group *RegistryGroup[T]) Unmarshal(namespace string, payload []byte) (object T, err error) {
+	group.lock.RLock()
+	registry, found := group.namespaces[namespace]
+	group.lock.RUnlock()
+	if !found {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentInvalid.With("namespace", namespace))
+	}
+	return registry.Unmarshal(payload)
+}
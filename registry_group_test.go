@@ -0,0 +1,58 @@
+package argo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestRegistryGroupIsolatesNamespaces(t *testing.T) {
+	group := argo.NewRegistryGroup[Something]("type")
+	group.Namespace("tenantA").Add(Something1{})
+	group.Namespace("tenantB").Add(Something2{})
+
+	if _, err := group.Unmarshal("tenantA", []byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+	if _, err := group.Unmarshal("tenantB", []byte(`{"type": "something1"}`)); err == nil {
+		t.Error("should have failed to unmarshal across namespaces")
+	} else if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+	if names := group.Namespaces(); !reflect.DeepEqual(names, []string{"tenantA", "tenantB"}) {
+		t.Errorf("unexpected namespaces: %v", names)
+	}
+}
+
+func TestRegistryGroupShouldFailUnknownNamespaces(t *testing.T) {
+	group := argo.NewRegistryGroup[Something]("type")
+
+	if _, err := group.Unmarshal("bogus", []byte(`{"type": "something1"}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.ArgumentInvalid) {
+		t.Errorf("error should be an ArgumentInvalid, got %s", err)
+	}
+}
+
+func TestRegistryGroupSharesConfiguration(t *testing.T) {
+	group := argo.NewRegistryGroup[Something]("type").Configure(func(registry *argo.TypeRegistry[Something]) {
+		registry.Strict()
+	})
+	group.Namespace("tenantA").Add(Something1{})
+
+	if _, err := group.Unmarshal("tenantA", []byte(`{"type": "something1", "bogus": true}`)); err == nil {
+		t.Error("should have failed to unmarshal with unknown fields")
+	}
+}
+
+func TestRegistryGroupCanDropNamespaces(t *testing.T) {
+	group := argo.NewRegistryGroup[Something]("type")
+	group.Namespace("tenantA").Add(Something1{})
+
+	group.DropNamespace("tenantA")
+	if names := group.Namespaces(); len(names) != 0 {
+		t.Errorf("expected no namespaces, got %v", names)
+	}
+}
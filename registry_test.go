@@ -0,0 +1,41 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestAllRegistriesSatisfyTheRegistryInterface(t *testing.T) {
+	registries := map[string]argo.Registry[Something]{
+		"type":     argo.NewTypeRegistry[Something]("type").Add(Something1{}),
+		"sync":     argo.NewSyncTypeRegistry[Something]("type").Add(Something1{}),
+		"sharded":  argo.NewShardedTypeRegistry[Something](4, "type").Add(Something1{}),
+		"property": argo.NewPropertyRegistry[Something]().Add(Something1{}),
+		"syncprop": argo.NewSyncPropertyRegistry[Something]().Add(Something1{}),
+	}
+	payloads := map[string][]byte{
+		"type":     []byte(`{"type": "something1", "text": "hello"}`),
+		"sync":     []byte(`{"type": "something1", "text": "hello"}`),
+		"sharded":  []byte(`{"type": "something1", "text": "hello"}`),
+		"property": []byte(`{"something1": {"text": "hello"}}`),
+		"syncprop": []byte(`{"something1": {"text": "hello"}}`),
+	}
+	for name, registry := range registries {
+		if size := registry.Size(); size != 1 {
+			t.Errorf("%s: expected 1 type, got %d", name, size)
+		}
+		object, err := registry.Unmarshal(payloads[name])
+		if err != nil {
+			t.Errorf("%s: failed to unmarshal: %s", name, err)
+			continue
+		}
+		if _, ok := object.(*Something1); !ok {
+			t.Errorf("%s: expected a *Something1, got %T", name, object)
+		}
+		registry.Remove("something1")
+		if size := registry.Size(); size != 0 {
+			t.Errorf("%s: expected 0 types after removal, got %d", name, size)
+		}
+	}
+}
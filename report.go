@@ -0,0 +1,104 @@
+package argo
+
+import (
+	"reflect"
+	"time"
+)
+
+// DecodeReport carries the numbers of one decode, for capacity planning at
+// the decode layer
+type DecodeReport struct {
+	// Type is the canonical type name the payload decoded into
+	Type string `json:"type"`
+	// PayloadSize is the size of the JSON payload in bytes
+	PayloadSize int `json:"payloadSize"`
+	// ObjectSize approximates the decoded object's memory footprint in
+	// bytes, including the heap data it points to
+	ObjectSize int `json:"objectSize"`
+	// Duration is how long the decode took
+	Duration time.Duration `json:"duration"`
+}
+
+// UnmarshalWithReport unmarshals like Unmarshal and reports the payload
+// size, the decoded object's approximate size and the decode duration.
+//
+// The report is valid even when the decode fails; its object size is then
+// zero.
+func (registry *TypeRegistry[T]) UnmarshalWithReport(payload []byte) (T, DecodeReport, error) {
+	report := DecodeReport{PayloadSize: len(payload)}
+	start := time.Now()
+	object, err := registry.Unmarshal(payload)
+	report.Duration = time.Since(start)
+	if err != nil {
+		return object, report, err
+	}
+	report.Type = object.GetType()
+	report.ObjectSize = approximateSize(reflect.ValueOf(object), map[uintptr]bool{})
+	return object, report, nil
+}
+
+// approximateSize estimates the memory footprint of a value in bytes.
+//
+// The estimate walks pointers, slices, maps and strings and counts the heap
+// data they reference, each referenced block once. It is an approximation:
+// allocator overhead, spare slice capacity of nested values and unexported
+// runtime details are not accounted for.
+func approximateSize(value reflect.Value, seen map[uintptr]bool) int {
+	if !value.IsValid() {
+		return 0
+	}
+	switch value.Kind() {
+	case reflect.Pointer:
+		if value.IsNil() {
+			return int(value.Type().Size())
+		}
+		pointer := value.Pointer()
+		if seen[pointer] {
+			return int(value.Type().Size())
+		}
+		seen[pointer] = true
+		return int(value.Type().Size()) + approximateSize(value.Elem(), seen)
+	case reflect.Interface:
+		if value.IsNil() {
+			return int(value.Type().Size())
+		}
+		return int(value.Type().Size()) + approximateSize(value.Elem(), seen)
+	case reflect.String:
+		return int(value.Type().Size()) + value.Len()
+	case reflect.Slice:
+		if value.IsNil() {
+			return int(value.Type().Size())
+		}
+		size := int(value.Type().Size())
+		for index := 0; index < value.Len(); index++ {
+			size += approximateSize(value.Index(index), seen)
+		}
+		size += (value.Cap() - value.Len()) * int(value.Type().Elem().Size())
+		return size
+	case reflect.Array:
+		size := 0
+		for index := 0; index < value.Len(); index++ {
+			size += approximateSize(value.Index(index), seen)
+		}
+		return size
+	case reflect.Map:
+		if value.IsNil() {
+			return int(value.Type().Size())
+		}
+		size := int(value.Type().Size())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			size += approximateSize(iterator.Key(), seen)
+			size += approximateSize(iterator.Value(), seen)
+		}
+		return size
+	case reflect.Struct:
+		size := 0
+		for index := 0; index < value.NumField(); index++ {
+			size += approximateSize(value.Field(index), seen)
+		}
+		return size
+	default:
+		return int(value.Type().Size())
+	}
+}
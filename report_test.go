@@ -0,0 +1,64 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestUnmarshalWithReportMeasuresTheDecode(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	payload := []byte(`{"type": "something1", "text": "hello"}`)
+	object, report, err := registry.UnmarshalWithReport(payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if report.Type != "something1" {
+		t.Errorf(`expected type "something1", got %q`, report.Type)
+	}
+	if report.PayloadSize != len(payload) {
+		t.Errorf("expected payload size %d, got %d", len(payload), report.PayloadSize)
+	}
+	if report.ObjectSize <= 0 {
+		t.Errorf("expected a positive object size, got %d", report.ObjectSize)
+	}
+	if report.Duration <= 0 {
+		t.Errorf("expected a positive duration, got %s", report.Duration)
+	}
+}
+
+func TestUnmarshalWithReportGrowsWithTheObject(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	_, small, err := registry.UnmarshalWithReport([]byte(`{"type": "something1", "text": "a"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	_, large, err := registry.UnmarshalWithReport([]byte(`{"type": "something1", "text": "a much longer text that takes more memory"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if large.ObjectSize <= small.ObjectSize {
+		t.Errorf("expected the larger object to report more memory: %d <= %d", large.ObjectSize, small.ObjectSize)
+	}
+}
+
+func TestUnmarshalWithReportOnFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	payload := []byte(`{"type": "whatever"}`)
+	_, report, err := registry.UnmarshalWithReport(payload)
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if report.PayloadSize != len(payload) {
+		t.Errorf("expected payload size %d, got %d", len(payload), report.PayloadSize)
+	}
+	if report.ObjectSize != 0 {
+		t.Errorf("expected no object size, got %d", report.ObjectSize)
+	}
+}
@@ -0,0 +1,118 @@
+package argo
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gildas/argo/core"
+)
+
+// TypeResolver supplies a type for a discriminator the registry does not
+// know yet, e.g. by asking a schema registry. Returning an error leaves the
+// discriminator unknown; the error is reported to the caller that triggered
+// the resolution.
+type TypeResolver[T core.TypeCarrier] func(typename string) (T, error)
+
+// flightGroup runs at most one resolution per discriminator at a time and
+// caches the outcomes.
+//
+// This is a minimal singleflight: the first caller for a discriminator runs
+// the resolution, concurrent callers for the same discriminator wait for it
+// and share its outcome. Resolved types live in the group's own cache,
+// guarded by its mutex, so resolutions never mutate the registry maps under
+// concurrent lookups.
+type flightGroup struct {
+	mutex    sync.Mutex
+	flights  map[string]*flight
+	resolved map[string]reflect.Type
+}
+
+// flight is one in-progress resolution
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// do runs the given resolution for the given discriminator, unless one is
+// already in progress, in which case it waits for it and shares its error
+func (group *flightGroup) do(typename string, resolve func() error) error {
+	group.mutex.Lock()
+	if group.flights == nil {
+		group.flights = map[string]*flight{}
+	}
+	if current, found := group.flights[typename]; found {
+		group.mutex.Unlock()
+		<-current.done
+		return current.err
+	}
+	current := &flight{done: make(chan struct{})}
+	group.flights[typename] = current
+	group.mutex.Unlock()
+
+	current.err = resolve()
+
+	group.mutex.Lock()
+	delete(group.flights, typename)
+	group.mutex.Unlock()
+	close(current.done)
+	return current.err
+}
+
+// lookup gives the cached type for the given discriminator, if any
+func (group *flightGroup) lookup(typename string) (reflect.Type, bool) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	class, found := group.resolved[typename]
+	return class, found
+}
+
+// store caches the resolved type for the given discriminator
+func (group *flightGroup) store(typename string, class reflect.Type) {
+	group.mutex.Lock()
+	defer group.mutex.Unlock()
+	if group.resolved == nil {
+		group.resolved = map[string]reflect.Type{}
+	}
+	group.resolved[typename] = class
+}
+
+// WithTypeResolver gives the registry a resolver for unknown discriminators.
+//
+// When a payload carries a discriminator that matches no registered type,
+// the resolver is asked for a class and the payload is decoded into it;
+// the answer is cached, so subsequent payloads find the type without asking
+// again. Resolutions are guarded per discriminator, so a burst of
+// unknown-type payloads triggers exactly one resolution per discriminator
+// rather than a stampede. Resolved types live in a cache of their own and
+// do not count towards Size.
+//
+// The resolver takes precedence over WithUnknownTypeHandler and
+// WithDefaultType, which keep handling discriminators the resolver fails on.
+func (registry *TypeRegistry[T]) WithTypeResolver(resolver TypeResolver[T]) *TypeRegistry[T] {
+	registry.typeResolver = resolver
+	return registry
+}
+
+// resolveUnknown asks the type resolver for the given discriminator and
+// caches its answer, one resolution per discriminator at a time
+func (registry *TypeRegistry[T]) resolveUnknown(typename string) (string, reflect.Type, bool) {
+	err := registry.flights.do(typename, func() error {
+		if _, found := registry.flights.lookup(typename); found {
+			return nil
+		}
+		class, err := registry.typeResolver(typename)
+		if err != nil {
+			return err
+		}
+		registry.flights.store(typename, dereferenceType(reflect.TypeOf(class)))
+		return nil
+	})
+	if err != nil {
+		if registry.logger != nil {
+			registry.logger.Debug("type resolution failed", "type", typename, "error", err)
+		}
+		return typename, nil, false
+	}
+	class, found := registry.flights.lookup(typename)
+	return typename, class, found
+}
@@ -0,0 +1,75 @@
+package argo_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestTypeResolverRegistersUnknownTypes(t *testing.T) {
+	calls := int32(0)
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		WithTypeResolver(func(typename string) (Something, error) {
+			atomic.AddInt32(&calls, 1)
+			return Something2{}, nil
+		})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 3}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something2 := object.(*Something2); something2.Value != 3 {
+		t.Errorf("expected 3, got %d", something2.Value)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 5}`)); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 resolution, got %d", calls)
+	}
+}
+
+func TestTypeResolverRunsOncePerBurst(t *testing.T) {
+	calls := int32(0)
+	gate := make(chan struct{})
+	registry := argo.NewTypeRegistry[Something]().
+		WithTypeResolver(func(typename string) (Something, error) {
+			atomic.AddInt32(&calls, 1)
+			<-gate
+			return Something1{}, nil
+		})
+
+	var group sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+				t.Errorf("failed to unmarshal: %s", err)
+			}
+		}()
+	}
+	close(gate)
+	group.Wait()
+	if calls != 1 {
+		t.Errorf("expected 1 resolution, got %d", calls)
+	}
+}
+
+func TestTypeResolverFailuresFallThrough(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().
+		WithTypeResolver(func(typename string) (Something, error) {
+			return nil, errors.NotImplemented
+		})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "whatever"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
@@ -0,0 +1,76 @@
+package argo
+
+import (
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Family names one of the interface families a Router can route to, e.g.
+// "commands", "events" or "queries"
+type Family string
+
+// Router dispatches payloads across several registries holding different
+// interface families.
+//
+// A single inbound socket often carries commands, events and queries at
+// once; the router tries each family's registry in the order they were
+// added and tells the caller which family recognized the payload.
+type Router struct {
+	families []routedFamily
+}
+
+// routedFamily is one registry under its family name, with its T erased
+type routedFamily struct {
+	family    Family
+	unmarshal func(payload []byte) (any, error)
+}
+
+// NewRouter creates a new Router
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// AddFamily adds a registry to the router under the given family name.
+//
+// Families are tried in the order they are added; this is a function rather
+// than a method because each family's registry carries its own type
+// parameter.
+func AddFamily[T core.TypeCarrier](router *Router, family Family, registry *TypeRegistry[T]) *Router {
+	router.families = append(router.families, routedFamily{
+		family: family,
+		unmarshal: func(payload []byte) (any, error) {
+			object, err := registry.Unmarshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			return object, nil
+		},
+	})
+	return router
+}
+
+// Route unmarshals the given payload through the first family that
+// recognizes it.
+//
+// A registry that does not know the payload's discriminator (or finds none)
+// passes its turn; any other failure, e.g. malformed JSON in a recognized
+// type, stops the routing and is reported with the family that claimed the
+// payload. If no family recognizes the payload, an errors.InvalidType
+// listing the families is returned, wrapped in an errors.JSONUnmarshalError.
+func (router *Router) Route(payload []byte) (any, Family, error) {
+	for _, candidate := range router.families {
+		object, err := candidate.unmarshal(payload)
+		if err == nil {
+			return object, candidate.family, nil
+		}
+		if errors.Is(err, errors.InvalidType) || errors.Is(err, errors.ArgumentMissing) {
+			continue
+		}
+		return nil, candidate.family, err
+	}
+	families := make([]string, 0, len(router.families))
+	for _, candidate := range router.families {
+		families = append(families, string(candidate.family))
+	}
+	return nil, "", errors.JSONUnmarshalError.Wrap(errors.InvalidType.With("payload", families))
+}
@@ -0,0 +1,76 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+type Command interface {
+	Something
+}
+
+type CreateCommand struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+func (command CreateCommand) GetType() string {
+	return "create"
+}
+
+func TestRouterRoutesAcrossFamilies(t *testing.T) {
+	commands := argo.NewTypeRegistry[Command]().Add(CreateCommand{})
+	events := argo.NewTypeRegistry[Something]().Add(Something1{})
+	router := argo.NewRouter()
+	argo.AddFamily(router, "commands", commands)
+	argo.AddFamily(router, "events", events)
+
+	object, family, err := router.Route([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to route: %s", err)
+	}
+	if family != "events" {
+		t.Errorf(`expected family "events", got %q`, family)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+
+	if _, family, err = router.Route([]byte(`{"type": "create", "name": "thing"}`)); err != nil {
+		t.Fatalf("failed to route: %s", err)
+	} else if family != "commands" {
+		t.Errorf(`expected family "commands", got %q`, family)
+	}
+}
+
+func TestRouterFailsWhenNoFamilyRecognizesThePayload(t *testing.T) {
+	router := argo.NewRouter()
+	argo.AddFamily(router, "events", argo.NewTypeRegistry[Something]().Add(Something1{}))
+
+	_, family, err := router.Route([]byte(`{"type": "whatever"}`))
+	if err == nil {
+		t.Fatal("should have failed to route")
+	}
+	if family != "" {
+		t.Errorf("expected no family, got %q", family)
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestRouterReportsTheClaimingFamilyOnFailure(t *testing.T) {
+	strict := argo.NewTypeRegistry[Something]().Add(Something2{}).Strict()
+	router := argo.NewRouter()
+	argo.AddFamily(router, "events", strict)
+
+	_, family, err := router.Route([]byte(`{"type": "something2", "value": 3, "bogus": true}`))
+	if err == nil {
+		t.Fatal("should have failed to route")
+	}
+	if family != "events" {
+		t.Errorf(`expected family "events", got %q`, family)
+	}
+}
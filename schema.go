@@ -0,0 +1,127 @@
+package argo
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONSchema produces a JSON Schema document describing the payloads the
+// registry accepts.
+//
+// The document is a oneOf across all registered types; each branch
+// constrains the discriminator with const (or enum when aliases exist) and
+// describes the struct's exported fields from their json tags. Consumers of
+// the API can use it to validate payloads and generate clients.
+//
+// The schema is returned as a map, ready for json.Marshal.
+func (registry *TypeRegistry[T]) JSONSchema() map[string]any {
+	names := make([]string, 0, len(registry.classes))
+	for name := range registry.classes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	branches := make([]any, 0, len(names))
+	definitions := map[string]any{}
+	for _, name := range names {
+		branches = append(branches, map[string]any{"$ref": "#/$defs/" + name})
+		definitions[name] = registry.typeSchema(name, registry.classes[name])
+	}
+	return map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"oneOf":   branches,
+		"$defs":   definitions,
+	}
+}
+
+// typeSchema describes one registered type, with its discriminator pinned
+func (registry *TypeRegistry[T]) typeSchema(name string, class reflect.Type) map[string]any {
+	schema := structSchema(class, map[reflect.Type]bool{})
+	properties, _ := schema["properties"].(map[string]any)
+	if properties == nil {
+		properties = map[string]any{}
+		schema["properties"] = properties
+	}
+	tag := registry.tags[0]
+	names := []any{name}
+	for alias, target := range registry.aliases {
+		if target == name {
+			names = append(names, alias)
+		}
+	}
+	if len(names) == 1 {
+		properties[tag] = map[string]any{"const": name}
+	} else {
+		sort.Slice(names[1:], func(i, j int) bool { return names[i+1].(string) < names[j+1].(string) })
+		properties[tag] = map[string]any{"enum": names}
+	}
+	schema["required"] = []any{tag}
+	return schema
+}
+
+// structSchema describes a struct's exported fields from their json tags
+func structSchema(class reflect.Type, visited map[reflect.Type]bool) map[string]any {
+	if visited[class] {
+		return map[string]any{"type": "object"}
+	}
+	visited[class] = true
+	defer delete(visited, class)
+
+	properties := map[string]any{}
+	for index := 0; index < class.NumField(); index++ {
+		field := class.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, found := field.Tag.Lookup("json"); found {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if len(parts[0]) > 0 {
+				name = parts[0]
+			}
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			embedded := structSchema(field.Type, visited)
+			if embeddedProperties, ok := embedded["properties"].(map[string]any); ok {
+				for key, value := range embeddedProperties {
+					properties[key] = value
+				}
+			}
+			continue
+		}
+		properties[name] = fieldSchema(field.Type, visited)
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// fieldSchema describes one Go type as a JSON Schema fragment
+func fieldSchema(class reflect.Type, visited map[reflect.Type]bool) map[string]any {
+	switch class.Kind() {
+	case reflect.Pointer:
+		return fieldSchema(class.Elem(), visited)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": fieldSchema(class.Elem(), visited)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": fieldSchema(class.Elem(), visited)}
+	case reflect.Struct:
+		return structSchema(class, visited)
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,64 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestJSONSchemaDescribesRegisteredTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	schema := registry.JSONSchema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("schema should marshal: %s", err)
+	}
+	branches := schema["oneOf"].([]any)
+	if len(branches) != 2 {
+		t.Fatalf("expected 2 branches, got %d", len(branches))
+	}
+	definitions := schema["$defs"].(map[string]any)
+	something1 := definitions["something1"].(map[string]any)
+	properties := something1["properties"].(map[string]any)
+	discriminator := properties["type"].(map[string]any)
+	if discriminator["const"] != "something1" {
+		t.Errorf(`expected const "something1", got %v`, discriminator)
+	}
+	if text := properties["text"].(map[string]any); text["type"] != "string" {
+		t.Errorf("expected a string text property, got %v", text)
+	}
+	required := something1["required"].([]any)
+	if len(required) != 1 || required[0] != "type" {
+		t.Errorf("expected the discriminator to be required, got %v", required)
+	}
+}
+
+func TestJSONSchemaListsAliasesAsEnum(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias("first", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	schema := registry.JSONSchema()
+	definitions := schema["$defs"].(map[string]any)
+	something1 := definitions["something1"].(map[string]any)
+	properties := something1["properties"].(map[string]any)
+	discriminator := properties["type"].(map[string]any)
+	values := discriminator["enum"].([]any)
+	if len(values) != 2 || values[0] != "something1" || values[1] != "first" {
+		t.Errorf("expected the canonical name and its alias, got %v", values)
+	}
+}
+
+func TestJSONSchemaDescribesNumbersAndSlices(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something2{})
+
+	schema := registry.JSONSchema()
+	definitions := schema["$defs"].(map[string]any)
+	something2 := definitions["something2"].(map[string]any)
+	properties := something2["properties"].(map[string]any)
+	if value := properties["value"].(map[string]any); value["type"] != "integer" {
+		t.Errorf("expected an integer value property, got %v", value)
+	}
+}
@@ -0,0 +1,172 @@
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// WithSchemaValidation makes Unmarshal validate payloads against the given
+// JSON Schema before dispatching them.
+//
+// The validator is deliberately minimal: it understands type, properties,
+// required, items, enum, const, oneOf and local $ref ($defs), which covers
+// the documents JSONSchema produces. Violations fail the decode with an
+// errors.SchemaViolation carrying the JSON path of the offending value,
+// wrapped in an errors.JSONUnmarshalError.
+func (registry *TypeRegistry[T]) WithSchemaValidation(schema map[string]any) *TypeRegistry[T] {
+	registry.schema = schema
+	return registry
+}
+
+// ValidateSchema validates a JSON document against the given schema (see
+// WithSchemaValidation for the supported keywords)
+func ValidateSchema(schema map[string]any, payload []byte) error {
+	var value any
+
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	return validateSchema(schema, schema, value, "$")
+}
+
+// validateSchema validates one value against one schema fragment
+func validateSchema(root map[string]any, schema map[string]any, value any, path string) error {
+	if ref, found := schema["$ref"].(string); found {
+		resolved, err := resolveSchemaRef(root, ref)
+		if err != nil {
+			return err
+		}
+		return validateSchema(root, resolved, value, path)
+	}
+	if kind, found := schema["type"].(string); found {
+		if err := validateSchemaType(kind, value, path); err != nil {
+			return err
+		}
+	}
+	if expected, found := schema["const"]; found {
+		if !reflect.DeepEqual(expected, value) {
+			return errors.SchemaViolation.With(path, fmt.Sprintf("expected %v", expected))
+		}
+	}
+	if choices, found := schema["enum"].([]any); found {
+		matched := false
+		for _, choice := range choices {
+			if reflect.DeepEqual(choice, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return errors.SchemaViolation.With(path, fmt.Sprintf("expected one of %v", choices))
+		}
+	}
+	if object, ok := value.(map[string]any); ok {
+		if required, found := schema["required"].([]any); found {
+			for _, key := range required {
+				name, _ := key.(string)
+				if _, found := object[name]; !found {
+					return errors.SchemaViolation.With(path, fmt.Sprintf("missing required property %q", name))
+				}
+			}
+		}
+		if properties, found := schema["properties"].(map[string]any); found {
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				property, ok := properties[name].(map[string]any)
+				if !ok {
+					continue
+				}
+				if nested, found := object[name]; found {
+					if err := validateSchema(root, property, nested, path+"."+name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	if items, found := schema["items"].(map[string]any); found {
+		if elements, ok := value.([]any); ok {
+			for index, element := range elements {
+				if err := validateSchema(root, items, element, fmt.Sprintf("%s[%d]", path, index)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if branches, found := schema["oneOf"].([]any); found {
+		matches := 0
+		for _, branch := range branches {
+			fragment, ok := branch.(map[string]any)
+			if !ok {
+				continue
+			}
+			if validateSchema(root, fragment, value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return errors.SchemaViolation.With(path, fmt.Sprintf("matches %d oneOf branches, expected exactly 1", matches))
+		}
+	}
+	return nil
+}
+
+// validateSchemaType checks a value against a JSON Schema type name
+func validateSchemaType(kind string, value any, path string) error {
+	valid := false
+	switch kind {
+	case "object":
+		_, valid = value.(map[string]any)
+	case "array":
+		_, valid = value.([]any)
+	case "string":
+		_, valid = value.(string)
+	case "boolean":
+		_, valid = value.(bool)
+	case "number":
+		_, valid = value.(float64)
+	case "integer":
+		if number, ok := value.(float64); ok {
+			valid = number == math.Trunc(number)
+		}
+	case "null":
+		valid = value == nil
+	}
+	if !valid {
+		return errors.SchemaViolation.With(path, "expected a "+kind)
+	}
+	return nil
+}
+
+// resolveSchemaRef resolves a local $ref like "#/$defs/something1"
+func resolveSchemaRef(root map[string]any, ref string) (map[string]any, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, errors.ArgumentInvalid.With("$ref", ref)
+	}
+	var current any = root
+	for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, errors.ArgumentInvalid.With("$ref", ref)
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, errors.ArgumentInvalid.With("$ref", ref)
+		}
+	}
+	fragment, ok := current.(map[string]any)
+	if !ok {
+		return nil, errors.ArgumentInvalid.With("$ref", ref)
+	}
+	return fragment, nil
+}
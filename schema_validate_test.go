@@ -0,0 +1,88 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestValidateSchemaChecksTypesAndRequired(t *testing.T) {
+	schema := map[string]any{
+		"type":     "object",
+		"required": []any{"type"},
+		"properties": map[string]any{
+			"type": map[string]any{"const": "something1"},
+			"text": map[string]any{"type": "string"},
+		},
+	}
+
+	if err := argo.ValidateSchema(schema, []byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("payload should validate: %s", err)
+	}
+	err := argo.ValidateSchema(schema, []byte(`{"text": "hello"}`))
+	if err == nil {
+		t.Fatal("payload should not validate")
+	}
+	if !errors.Is(err, errors.SchemaViolation) {
+		t.Errorf("error should be a SchemaViolation, got %s", err)
+	}
+}
+
+func TestValidateSchemaReportsThePath(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"items": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "integer"},
+			},
+		},
+	}
+
+	err := argo.ValidateSchema(schema, []byte(`{"items": [1, "two"]}`))
+	if err == nil {
+		t.Fatal("payload should not validate")
+	}
+	if !strings.Contains(err.Error(), "$.items[1]") {
+		t.Errorf("error should point at $.items[1], got %s", err)
+	}
+}
+
+func TestValidateSchemaChecksEnums(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"level": map[string]any{"enum": []any{"low", "high"}},
+		},
+	}
+
+	if err := argo.ValidateSchema(schema, []byte(`{"level": "low"}`)); err != nil {
+		t.Errorf("payload should validate: %s", err)
+	}
+	if err := argo.ValidateSchema(schema, []byte(`{"level": "medium"}`)); err == nil {
+		t.Error("payload should not validate")
+	}
+}
+
+func TestWithSchemaValidationGuardsUnmarshal(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	registry.WithSchemaValidation(registry.JSONSchema())
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+
+	_, err = registry.Unmarshal([]byte(`{"type": "something1", "text": 3}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.SchemaViolation) {
+		t.Errorf("error should be a SchemaViolation, got %s", err)
+	}
+}
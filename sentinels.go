@@ -0,0 +1,28 @@
+package argo
+
+import "github.com/gildas/argo/errors"
+
+// Sentinels for the failure modes of Unmarshal, so callers can branch with
+// errors.Is without importing the errors sub-package or inspecting details:
+//
+//	switch {
+//	case errors.Is(err, argo.ErrMissingType):
+//		// the payload carries no type tag
+//	case errors.Is(err, argo.ErrUnknownType):
+//		// the discriminator matches no registered type
+//	case errors.Is(err, argo.ErrDecode):
+//		// the payload could not be decoded into the matched type
+//	}
+//
+// ErrDecode is the outer wrapper of every unmarshal failure, so it also
+// matches the first two modes; test for it last.
+var (
+	// ErrMissingType matches payloads carrying no type tag
+	ErrMissingType = errors.ArgumentMissing
+
+	// ErrUnknownType matches discriminators no type is registered for
+	ErrUnknownType = errors.InvalidType
+
+	// ErrDecode matches any unmarshal failure
+	ErrDecode = errors.JSONUnmarshalError
+)
@@ -0,0 +1,36 @@
+package argo_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestSentinelsDistinguishFailureModes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	_, err := registry.Unmarshal([]byte(`{"text": "hello"}`))
+	if !errors.Is(err, argo.ErrMissingType) {
+		t.Errorf("error should match ErrMissingType, got %v", err)
+	}
+	if errors.Is(err, argo.ErrUnknownType) {
+		t.Errorf("error should not match ErrUnknownType, got %v", err)
+	}
+
+	_, err = registry.Unmarshal([]byte(`{"type": "bogus"}`))
+	if !errors.Is(err, argo.ErrUnknownType) {
+		t.Errorf("error should match ErrUnknownType, got %v", err)
+	}
+	if errors.Is(err, argo.ErrMissingType) {
+		t.Errorf("error should not match ErrMissingType, got %v", err)
+	}
+
+	_, err = registry.Unmarshal([]byte(`{"type": "something1", "text": 42}`))
+	if !errors.Is(err, argo.ErrDecode) {
+		t.Errorf("error should match ErrDecode, got %v", err)
+	}
+	if errors.Is(err, argo.ErrMissingType) || errors.Is(err, argo.ErrUnknownType) {
+		t.Errorf("error should be a plain decode failure, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package argo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// ShardedTypeRegistry is a concurrency-safe TypeRegistry that spreads its
+// registrations over several independently locked shards.
+//
+// A hash of the type name selects the shard, so concurrent Add and Unmarshal
+// calls for different types contend on different locks. This trades a little
+// per-call overhead for scalability on very large type sets (thousands of
+// types registered and resolved concurrently); for small sets prefer
+// SyncTypeRegistry.
+type ShardedTypeRegistry[T core.TypeCarrier] struct {
+	tags   []string
+	shards []*registryShard[T]
+}
+
+// registryShard is one independently locked slice of the registrations
+type registryShard[T core.TypeCarrier] struct {
+	lock     sync.RWMutex
+	registry *TypeRegistry[T]
+}
+
+// NewShardedTypeRegistry creates a new ShardedTypeRegistry with the given
+// number of shards (16 when zero or negative) and type tags
+func NewShardedTypeRegistry[T core.TypeCarrier](shardCount int, tags ...string) *ShardedTypeRegistry[T] {
+	if shardCount <= 0 {
+		shardCount = 16
+	}
+	shards := make([]*registryShard[T], shardCount)
+	for index := range shards {
+		shards[index] = &registryShard[T]{registry: NewTypeRegistry[T](tags...)}
+	}
+	return &ShardedTypeRegistry[T]{
+		tags:   shards[0].registry.tags,
+		shards: shards,
+	}
+}
+
+// Add adds one or more types to the registry, keyed by their type name
+func (registry *ShardedTypeRegistry[T]) Add(classes ...T) *ShardedTypeRegistry[T] {
+	for _, class := range classes {
+		shard := registry.shardFor(class.GetType())
+		shard.lock.Lock()
+		shard.registry.Add(class)
+		shard.lock.Unlock()
+	}
+	return registry
+}
+
+// Remove removes a type from the registry
+func (registry *ShardedTypeRegistry[T]) Remove(typename string) {
+	shard := registry.shardFor(typename)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+	shard.registry.Remove(typename)
+}
+
+// Size gives the number of types registered across all shards
+func (registry *ShardedTypeRegistry[T]) Size() int {
+	size := 0
+	for _, shard := range registry.shards {
+		shard.lock.RLock()
+		size += shard.registry.Size()
+		shard.lock.RUnlock()
+	}
+	return size
+}
+
+// Types gives the sorted type names registered across all shards
+func (registry *ShardedTypeRegistry[T]) Types() []string {
+	types := []string{}
+	for _, shard := range registry.shards {
+		shard.lock.RLock()
+		types = append(types, shard.registry.types...)
+		shard.lock.RUnlock()
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Unmarshal unmarshals the given payload into the type matching its
+// discriminator, locking only the shard that type lives on
+func (registry *ShardedTypeRegistry[T]) Unmarshal(payload []byte) (object T, err error) {
+	typename, found, err := scanDiscriminator(payload, registry.tags)
+	if err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if !found {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+	}
+	shard := registry.shardFor(typename)
+	shard.lock.RLock()
+	defer shard.lock.RUnlock()
+	if _, _, known := shard.registry.resolve(typename); !known {
+		return object, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(typename, registry.supportedTypes()))
+	}
+	return shard.registry.Unmarshal(payload)
+}
+
+// shardFor gives the shard the given type name lives on
+func (registry *ShardedTypeRegistry[T]) shardFor(typename string) *registryShard[T] {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(typename))
+	return registry.shards[hash.Sum32()%uint32(len(registry.shards))]
+}
+
+// supportedTypes gives the sorted type names registered across all shards
+func (registry *ShardedTypeRegistry[T]) supportedTypes() string {
+	return fmt.Sprintf("%v", registry.Types())
+}
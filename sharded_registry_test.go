@@ -0,0 +1,76 @@
+package argo_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+// namedThing carries its type name, so tests can register many distinct
+// types from one struct
+type namedThing struct {
+	Name string `json:"name"`
+}
+
+func (thing namedThing) GetType() string {
+	return thing.Name
+}
+
+func TestCanUnmarshalTypeWithShardedRegistry(t *testing.T) {
+	registry := argo.NewShardedTypeRegistry[Something](8, "type").Add(Something1{}, Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if size := registry.Size(); size != 2 {
+		t.Errorf("expected 2 types, got %d", size)
+	}
+}
+
+func TestShouldFailUnmarshalUnknownTypeWithShardedRegistry(t *testing.T) {
+	registry := argo.NewShardedTypeRegistry[Something](0).Add(Something1{})
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "bogus"}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestShardedRegistryReadsDuringWrites(t *testing.T) {
+	registry := argo.NewShardedTypeRegistry[Something](8, "type").Add(Something1{})
+	payload := []byte(`{"type": "something1", "text": "hello"}`)
+
+	var group sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for iteration := 0; iteration < 100; iteration++ {
+				if _, err := registry.Unmarshal(payload); err != nil {
+					t.Errorf("failed to unmarshal: %s", err)
+					return
+				}
+			}
+		}()
+	}
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		for iteration := 0; iteration < 100; iteration++ {
+			registry.Add(namedThing{Name: fmt.Sprintf("event-%d", iteration)})
+		}
+	}()
+	group.Wait()
+}
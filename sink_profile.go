@@ -0,0 +1,159 @@
+package argo
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// SinkProfile describes how exported maps are sanitized for a given sink.
+//
+// Schemaless sinks are picky about keys: Elasticsearch interprets dots as
+// sub-field separators and reserves keys starting with "_", BigQuery rejects
+// dots in column names, both dislike deeply nested documents. A profile
+// captures those rules once so every export is sanitized the same way.
+type SinkProfile struct {
+	// DotReplacement replaces dots in keys when non-empty
+	DotReplacement string
+	// ReservedPrefixes lists key prefixes the sink reserves for itself
+	ReservedPrefixes []string
+	// PrefixReplacement substitutes a matched reserved prefix (can be empty to strip it)
+	PrefixReplacement string
+	// MaxDepth flattens objects nested deeper than this many levels (0 means no flattening)
+	MaxDepth int
+	// FlattenSeparator joins flattened keys (default: "_")
+	FlattenSeparator string
+	// LargeIntAsString emits integers beyond JavaScript's safe range (±2^53-1) as strings
+	LargeIntAsString bool
+	// FloatAsString emits every floating point number as a string
+	FloatAsString bool
+}
+
+// maxSafeInteger is the largest integer JavaScript (and BigQuery's JSON
+// ingestion) can represent without loss
+const maxSafeInteger = 1<<53 - 1
+
+// ElasticsearchProfile sanitizes maps for Elasticsearch documents
+var ElasticsearchProfile = SinkProfile{
+	DotReplacement:    "_",
+	ReservedPrefixes:  []string{"_"},
+	PrefixReplacement: "",
+}
+
+// BigQueryProfile sanitizes maps for BigQuery rows
+var BigQueryProfile = SinkProfile{
+	DotReplacement:   "_",
+	MaxDepth:         15,
+	FlattenSeparator: "_",
+	LargeIntAsString: true,
+}
+
+// ToMapForSink converts the given object into a map sanitized for the given sink.
+//
+// The type tag is injected first (see ToMap), then keys are sanitized, so the
+// discriminator key itself obeys the sink's rules.
+//
+// Numbers are decoded without loss (json.Number) so the profile's number
+// rules (LargeIntAsString, FloatAsString) apply to the real values.
+func (registry *TypeRegistry[T]) ToMapForSink(object T, profile SinkProfile) (map[string]any, error) {
+	data, err := registry.toMap(object, true)
+	if err != nil {
+		return nil, err
+	}
+	return profile.Sanitize(data), nil
+}
+
+// Sanitize applies the profile to the given map, returning a new map.
+//
+// The given map is not modified.
+func (profile SinkProfile) Sanitize(data map[string]any) map[string]any {
+	return profile.sanitize(data, 1)
+}
+
+func (profile SinkProfile) sanitize(data map[string]any, depth int) map[string]any {
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		key = profile.sanitizeKey(key)
+		switch actual := value.(type) {
+		case map[string]any:
+			if profile.MaxDepth > 0 && depth >= profile.MaxDepth {
+				for childKey, childValue := range profile.sanitize(actual, depth) {
+					result[key+profile.separator()+childKey] = childValue
+				}
+			} else {
+				result[key] = profile.sanitize(actual, depth+1)
+			}
+		case []any:
+			result[key] = profile.sanitizeSlice(actual, depth+1)
+		case json.Number:
+			result[key] = profile.formatNumber(actual)
+		default:
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func (profile SinkProfile) sanitizeSlice(data []any, depth int) []any {
+	result := make([]any, len(data))
+	for index, value := range data {
+		switch actual := value.(type) {
+		case map[string]any:
+			result[index] = profile.sanitize(actual, depth)
+		case []any:
+			result[index] = profile.sanitizeSlice(actual, depth+1)
+		case json.Number:
+			result[index] = profile.formatNumber(actual)
+		default:
+			result[index] = value
+		}
+	}
+	return result
+}
+
+// formatNumber applies the profile's number rules to the given number.
+//
+// Numbers stay json.Number (serialized unquoted) unless a rule asks for a string.
+func (profile SinkProfile) formatNumber(number json.Number) any {
+	if isInteger := !strings.ContainsAny(number.String(), ".eE"); isInteger {
+		if profile.LargeIntAsString {
+			if value, err := number.Int64(); err != nil || value > maxSafeInteger || value < -maxSafeInteger {
+				return number.String()
+			}
+		}
+		return number
+	}
+	if profile.FloatAsString {
+		return number.String()
+	}
+	return number
+}
+
+func (profile SinkProfile) sanitizeKey(key string) string {
+	for _, prefix := range profile.ReservedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			key = profile.PrefixReplacement + strings.TrimPrefix(key, prefix)
+			break
+		}
+	}
+	if len(profile.DotReplacement) > 0 {
+		key = strings.ReplaceAll(key, ".", profile.DotReplacement)
+	}
+	return key
+}
+
+func (profile SinkProfile) separator() string {
+	if len(profile.FlattenSeparator) > 0 {
+		return profile.FlattenSeparator
+	}
+	return "_"
+}
+
+// Validate checks the profile is usable
+func (profile SinkProfile) Validate() error {
+	if profile.MaxDepth < 0 {
+		return errors.ArgumentInvalid.With("MaxDepth", profile.MaxDepth)
+	}
+	return nil
+}
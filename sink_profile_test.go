@@ -0,0 +1,90 @@
+package argo_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestSinkProfileReplacesDotsInKeys(t *testing.T) {
+	profile := argo.SinkProfile{DotReplacement: "_"}
+	data := profile.Sanitize(map[string]any{"user.name": "bob"})
+	if data["user_name"] != "bob" {
+		t.Errorf("expected user_name, got %v", data)
+	}
+}
+
+func TestSinkProfileHandlesReservedPrefixes(t *testing.T) {
+	profile := argo.SinkProfile{ReservedPrefixes: []string{"_"}, PrefixReplacement: "x_"}
+	data := profile.Sanitize(map[string]any{"_id": "42"})
+	if data["x_id"] != "42" {
+		t.Errorf("expected x_id, got %v", data)
+	}
+}
+
+func TestSinkProfileFlattensDeepObjects(t *testing.T) {
+	profile := argo.SinkProfile{MaxDepth: 1, FlattenSeparator: "."}
+	data := profile.Sanitize(map[string]any{
+		"meta": map[string]any{"kind": map[string]any{"name": "event"}},
+	})
+	expected := map[string]any{"meta.kind.name": "event"}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("expected %v, got %v", expected, data)
+	}
+}
+
+func TestSinkProfileSanitizesInsideArrays(t *testing.T) {
+	profile := argo.SinkProfile{DotReplacement: "_"}
+	data := profile.Sanitize(map[string]any{
+		"items": []any{map[string]any{"a.b": 1}},
+	})
+	items := data["items"].([]any)
+	if _, found := items[0].(map[string]any)["a_b"]; !found {
+		t.Errorf("expected a_b inside array, got %v", data)
+	}
+}
+
+func TestToMapForSinkSanitizesTheExport(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("_type").Add(Something1{})
+	data, err := registry.ToMapForSink(Something1{Text: "hello"}, argo.SinkProfile{ReservedPrefixes: []string{"_"}, PrefixReplacement: "x_"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["x_type"] != "something1" {
+		t.Errorf("expected sanitized type tag, got %v", data)
+	}
+}
+
+type BigValues struct {
+	Type  string  `json:"type"`
+	ID    int64   `json:"id"`
+	Score float64 `json:"score"`
+}
+
+func (b BigValues) GetType() string { return "big" }
+
+func TestSinkProfileEmitsLargeIntsAsStrings(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(BigValues{})
+	data, err := registry.ToMapForSink(BigValues{ID: 9007199254740993, Score: 1.5}, argo.SinkProfile{LargeIntAsString: true})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["id"] != "9007199254740993" {
+		t.Errorf("expected a string id, got %v (%T)", data["id"], data["id"])
+	}
+	if _, isString := data["score"].(string); isString {
+		t.Errorf("score should stay a number, got %v", data["score"])
+	}
+}
+
+func TestSinkProfileEmitsFloatsAsStrings(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(BigValues{})
+	data, err := registry.ToMapForSink(BigValues{ID: 42, Score: 1.5}, argo.SinkProfile{FloatAsString: true})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["score"] != "1.5" {
+		t.Errorf("expected a string score, got %v (%T)", data["score"], data["score"])
+	}
+}
@@ -0,0 +1,73 @@
+package argo
+
+import "github.com/gildas/argo/core"
+
+// RegistrySnapshot is an immutable capture of a registry's registrations
+// and configuration, taken with Snapshot and applied with Restore
+type RegistrySnapshot[T core.TypeCarrier] struct {
+	registry *TypeRegistry[T]
+}
+
+// Snapshot captures the registry's current registrations and configuration.
+//
+// The snapshot is detached: mutating the registry afterwards does not
+// change it, and it can be restored any number of times. Tests and
+// hot-reload flows can thus save the state, mutate it temporarily, and roll
+// back deterministically.
+func (registry *TypeRegistry[T]) Snapshot() RegistrySnapshot[T] {
+	return RegistrySnapshot[T]{registry: registry.clone()}
+}
+
+// Restore resets the registry to the given snapshot's state
+func (registry *TypeRegistry[T]) Restore(snapshot RegistrySnapshot[T]) {
+	registry.copyFrom(snapshot.registry.clone())
+}
+
+// Snapshot captures the registry's current registrations and configuration
+// (see TypeRegistry.Snapshot)
+func (registry *SyncTypeRegistry[T]) Snapshot() RegistrySnapshot[T] {
+	return RegistrySnapshot[T]{registry: registry.snapshot.Load().clone()}
+}
+
+// Restore atomically resets the registry to the given snapshot's state
+func (registry *SyncTypeRegistry[T]) Restore(snapshot RegistrySnapshot[T]) {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	registry.snapshot.Store(snapshot.registry.clone())
+}
+
+// copyFrom overwrites the registry's registrations and configuration with
+// the given ones, in place, leaving the resolver-flight cache untouched
+func (registry *TypeRegistry[T]) copyFrom(other *TypeRegistry[T]) {
+	registry.tags = other.tags
+	registry.types = other.types
+	registry.classes = other.classes
+	registry.aliases = other.aliases
+	registry.marshalHooks = other.marshalHooks
+	registry.failOnTagConflict = other.failOnTagConflict
+	registry.defaultClass = other.defaultClass
+	registry.unknownHandler = other.unknownHandler
+	registry.typeResolver = other.typeResolver
+	registry.unmarshalHooks = other.unmarshalHooks
+	registry.typeUnmarshalHooks = other.typeUnmarshalHooks
+	registry.strict = other.strict
+	registry.useNumber = other.useNumber
+	registry.caseInsensitive = other.caseInsensitive
+	registry.folded = other.folded
+	registry.logger = other.logger
+	registry.copyPayloads = other.copyPayloads
+	registry.validateObjects = other.validateObjects
+	registry.versionTag = other.versionTag
+	registry.versions = other.versions
+	registry.defaultVersions = other.defaultVersions
+	registry.migrations = other.migrations
+	registry.schema = other.schema
+	registry.frozen = other.frozen
+	registry.maxReportedTypes = other.maxReportedTypes
+	registry.observer = other.observer
+	registry.tracer = other.tracer
+	registry.codec = other.codec
+	registry.backend = other.backend
+	registry.checksum = other.checksum
+	registry.envelope = other.envelope
+}
@@ -0,0 +1,57 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestCanRestoreRegistrySnapshot(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	snapshot := registry.Snapshot()
+	registry.Add(Something2{})
+	registry.Remove("something1")
+
+	registry.Restore(snapshot)
+	if size := registry.Size(); size != 1 {
+		t.Errorf("expected 1 type, got %d", size)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2"}`)); err == nil {
+		t.Error("should have failed to unmarshal the rolled back type")
+	}
+}
+
+func TestSnapshotIsDetachedFromTheRegistry(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	snapshot := registry.Snapshot()
+	registry.Add(Something2{})
+
+	// restoring twice from the same snapshot must roll back both times
+	for round := 0; round < 2; round++ {
+		registry.Restore(snapshot)
+		if size := registry.Size(); size != 1 {
+			t.Fatalf("round %d: expected 1 type, got %d", round, size)
+		}
+		registry.Add(Something2{})
+	}
+}
+
+func TestSyncRegistryCanRestoreSnapshot(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]("type").Add(Something1{})
+
+	snapshot := registry.Snapshot()
+	registry.ReplaceAll(Something2{})
+
+	registry.Restore(snapshot)
+	if size := registry.Size(); size != 1 {
+		t.Errorf("expected 1 type, got %d", size)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
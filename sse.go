@@ -0,0 +1,127 @@
+package argo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// SSEOptions configures how a Server-Sent Events stream is read.
+type SSEOptions struct {
+	// SkipOnError keeps reading past events that fail to decode, instead of
+	// stopping the stream at the first failure
+	SkipOnError bool
+	// OnError is told about every event that failed to decode, with its
+	// event name; it may be nil
+	OnError func(event string, payload []byte, err error)
+	// MaxEventSize caps the size of a single line in bytes (default: 1 MiB)
+	MaxEventSize int
+}
+
+// ReadSSE reads Server-Sent Events from the given reader and hands every
+// decoded object to the given callback, along with the event name.
+//
+// Frames are parsed per the SSE wire format: "event:" names the event,
+// "data:" lines accumulate the payload (joined with newlines), a blank line
+// dispatches the frame; comments, ids and retry hints are ignored. Named
+// events whose name is a registered type (or alias) are decoded as that
+// type; other frames, including the default "message" events, dispatch on
+// the JSON body's type tag. Decode failures stop the stream unless the
+// options ask to skip-and-report; reader failures always stop the stream.
+// The callback may stop the stream early by returning an error, which is
+// passed through unwrapped.
+func (registry *TypeRegistry[T]) ReadSSE(reader io.Reader, options SSEOptions, callback func(event string, object T) error) error {
+	scanner := bufio.NewScanner(reader)
+	maxEventSize := options.MaxEventSize
+	if maxEventSize == 0 {
+		maxEventSize = 1024 * 1024
+	}
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEventSize)
+
+	event := ""
+	data := [][]byte{}
+	flush := func() error {
+		defer func() { event = ""; data = nil }()
+		if len(data) == 0 {
+			return nil
+		}
+		payload := bytes.Join(data, []byte("\n"))
+		var object T
+		var err error
+		if _, _, found := registry.resolve(event); event != "" && found {
+			object, err = registry.decodeResolved(context.Background(), event, payload, nil)
+		} else {
+			object, err = registry.Unmarshal(payload)
+		}
+		if err != nil {
+			if options.OnError != nil {
+				options.OnError(event, payload, err)
+			}
+			if options.SkipOnError {
+				return nil
+			}
+			return err
+		}
+		return callback(event, object)
+	}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value, found := bytes.Cut(line, []byte(":"))
+		if !found {
+			field, value = line, nil
+		}
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			event = string(value)
+		case "data":
+			data = append(data, append([]byte(nil), value...))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	return flush()
+}
+
+// StreamSSE reads Server-Sent Events from the given reader and emits
+// decoded objects on the returned channel.
+//
+// The channel is closed when the stream ends, fails, or the context is
+// canceled; the returned function reports the terminal error, if any, once
+// the channel is closed.
+func StreamSSE[T core.TypeCarrier](ctx context.Context, registry *TypeRegistry[T], reader io.Reader, options SSEOptions) (<-chan T, func() error) {
+	objects := make(chan T, pipelineBuffer)
+	var failure error
+
+	done := make(chan struct{})
+	go func() {
+		defer close(objects)
+		defer close(done)
+		failure = registry.ReadSSE(reader, options, func(event string, object T) error {
+			select {
+			case objects <- object:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return objects, func() error {
+		<-done
+		return failure
+	}
+}
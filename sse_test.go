@@ -0,0 +1,112 @@
+package argo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestCanReadSSEStreams(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+
+	stream := strings.Join([]string{
+		": keepalive",
+		"event: something1",
+		"data: {\"text\": \"hello\"}",
+		"",
+		"id: 42",
+		"data: {\"type\": \"something2\", \"value\": 7}",
+		"",
+	}, "\n")
+
+	events := []string{}
+	objects := []Something{}
+	err := registry.ReadSSE(strings.NewReader(stream), argo.SSEOptions{}, func(event string, object Something) error {
+		events = append(events, event)
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read stream: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	something1, ok := objects[0].(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", objects[0])
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+	if events[0] != "something1" || events[1] != "" {
+		t.Errorf("unexpected event names: %v", events)
+	}
+	if _, ok := objects[1].(*Something2); !ok {
+		t.Errorf("expected a *Something2, got %T", objects[1])
+	}
+}
+
+func TestSSEJoinsMultilineData(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	stream := "event: something1\ndata: {\"text\":\ndata: \"hello\"}\n\n"
+	count := 0
+	err := registry.ReadSSE(strings.NewReader(stream), argo.SSEOptions{}, func(event string, object Something) error {
+		count++
+		if object.(*Something1).Text != "hello" {
+			t.Errorf("unexpected object: %+v", object)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read stream: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 object, got %d", count)
+	}
+}
+
+func TestSSECanSkipFailedEvents(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	stream := "data: {\"type\": \"bogus\"}\n\ndata: {\"type\": \"something1\", \"text\": \"hello\"}\n\n"
+	if err := registry.ReadSSE(strings.NewReader(stream), argo.SSEOptions{}, func(event string, object Something) error { return nil }); err == nil {
+		t.Error("should have failed to read stream")
+	}
+
+	failed := 0
+	count := 0
+	options := argo.SSEOptions{
+		SkipOnError: true,
+		OnError:     func(event string, payload []byte, err error) { failed++ },
+	}
+	if err := registry.ReadSSE(strings.NewReader(stream), options, func(event string, object Something) error {
+		count++
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to read stream: %s", err)
+	}
+	if failed != 1 || count != 1 {
+		t.Errorf("expected 1 failure and 1 object, got %d and %d", failed, count)
+	}
+}
+
+func TestCanStreamSSE(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	stream := "event: something1\ndata: {\"text\": \"hello\"}\n\n"
+	objects, wait := argo.StreamSSE(context.Background(), registry, strings.NewReader(stream), argo.SSEOptions{})
+	count := 0
+	for range objects {
+		count++
+	}
+	if err := wait(); err != nil {
+		t.Fatalf("stream failed: %s", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 object, got %d", count)
+	}
+}
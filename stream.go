@@ -0,0 +1,159 @@
+package argo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Result is one element of a concurrent stream decode
+type Result[T core.TypeCarrier] struct {
+	// Index is the 0-based position of the element in the stream
+	Index int
+	// Object is the decoded element, when Err is nil
+	Object T
+	// Err is why the element failed to decode, if it did
+	Err error
+}
+
+// StreamOption configures UnmarshalStream
+type StreamOption func(*streamOptions)
+
+// streamOptions collects what the stream options configure
+type streamOptions struct {
+	ordered bool
+}
+
+// WithOrderedResults makes UnmarshalStream deliver results in stream order,
+// at the cost of head-of-line blocking on slow elements
+func WithOrderedResults() StreamOption {
+	return func(options *streamOptions) {
+		options.ordered = true
+	}
+}
+
+// UnmarshalStream decodes a stream of polymorphic documents concurrently.
+//
+// The stream is a sequence of JSON documents (concatenated or
+// newline-delimited); elements are parsed off the reader sequentially, but
+// the per-element struct decoding runs in a pool of the given number of
+// workers, so large imports use more than one core. Results arrive in
+// completion order unless WithOrderedResults is given; every result carries
+// the element's stream index. A malformed stream yields one final failed
+// result and ends the stream; canceling the context ends it early.
+func (registry *TypeRegistry[T]) UnmarshalStream(ctx context.Context, reader io.Reader, workers int, options ...StreamOption) <-chan Result[T] {
+	config := streamOptions{}
+	for _, option := range options {
+		option(&config)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	results := make(chan Result[T], pipelineBuffer)
+
+	type job struct {
+		index   int
+		payload json.RawMessage
+		done    chan Result[T]
+	}
+	jobs := make(chan job, workers)
+	pending := make(chan chan Result[T], workers)
+
+	var group sync.WaitGroup
+	group.Add(workers)
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			defer group.Done()
+			for item := range jobs {
+				object, err := registry.UnmarshalContext(ctx, item.payload)
+				result := Result[T]{Index: item.index, Object: object, Err: err}
+				if item.done != nil {
+					item.done <- result
+					continue
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	emit := results
+	if config.ordered {
+		emit = make(chan Result[T], pipelineBuffer)
+		go func() {
+			defer close(results)
+			for done := range pending {
+				select {
+				case results <- <-done:
+				case <-ctx.Done():
+					return
+				}
+			}
+			for result := range emit {
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		decoder := json.NewDecoder(reader)
+		index := 0
+		failure := Result[T]{Index: -1}
+		for {
+			if ctx.Err() != nil {
+				break
+			}
+			var payload json.RawMessage
+			if err := decoder.Decode(&payload); err != nil {
+				if !errors.Is(err, io.EOF) {
+					failure = Result[T]{Index: index, Err: errors.JSONUnmarshalError.Wrap(err)}
+				}
+				break
+			}
+			item := job{index: index, payload: payload}
+			if config.ordered {
+				item.done = make(chan Result[T], 1)
+				select {
+				case pending <- item.done:
+				case <-ctx.Done(): // the reorderer is gone; don't block on its queue
+					item.done <- Result[T]{Index: index, Err: errors.JSONUnmarshalError.Wrap(ctx.Err())}
+					continue
+				}
+			}
+			select {
+			case jobs <- item:
+			case <-ctx.Done():
+				if item.done != nil {
+					item.done <- Result[T]{Index: index, Err: errors.JSONUnmarshalError.Wrap(ctx.Err())}
+				}
+			}
+			index++
+		}
+		close(jobs)
+		group.Wait()
+		if failure.Index >= 0 {
+			select {
+			case emit <- failure:
+			case <-ctx.Done():
+			}
+		}
+		if config.ordered {
+			close(pending)
+			close(emit)
+		} else {
+			close(results)
+		}
+	}()
+	return results
+}
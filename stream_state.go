@@ -0,0 +1,159 @@
+package argo
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/gildas/argo/errors"
+)
+
+// DedupWindow remembers the identities of recently seen messages.
+//
+// Use it as a pipeline stage to drop redeliveries: Seen reports whether an
+// identity was already recorded, recording it on the way. The window holds
+// at most Capacity identities, evicting the oldest first.
+type DedupWindow struct {
+	capacity int
+	order    []string
+	seen     map[string]bool
+}
+
+// NewDedupWindow creates a window remembering up to capacity identities
+func NewDedupWindow(capacity int) *DedupWindow {
+	return &DedupWindow{
+		capacity: capacity,
+		seen:     map[string]bool{},
+	}
+}
+
+// Seen tells whether the given identity was already recorded, and records it
+func (window *DedupWindow) Seen(id string) bool {
+	if window.seen[id] {
+		return true
+	}
+	window.seen[id] = true
+	window.order = append(window.order, id)
+	if window.capacity > 0 && len(window.order) > window.capacity {
+		delete(window.seen, window.order[0])
+		window.order = window.order[1:]
+	}
+	return false
+}
+
+// Size gives the number of identities currently remembered
+func (window *DedupWindow) Size() int {
+	return len(window.order)
+}
+
+// dedupState is the serialized form of a DedupWindow
+type dedupState struct {
+	Capacity int      `json:"capacity"`
+	Order    []string `json:"order"`
+}
+
+// ExportState serializes the window so it can be checkpointed across restarts
+func (window *DedupWindow) ExportState() ([]byte, error) {
+	payload, err := json.Marshal(dedupState{Capacity: window.capacity, Order: window.order})
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// ImportState restores a window from a checkpoint taken with ExportState
+func (window *DedupWindow) ImportState(payload []byte) error {
+	var state dedupState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	window.capacity = state.Capacity
+	window.order = state.Order
+	window.seen = make(map[string]bool, len(state.Order))
+	for _, id := range state.Order {
+		window.seen[id] = true
+	}
+	return nil
+}
+
+// ReorderBuffer releases payloads in sequence order.
+//
+// Out-of-order payloads are parked until the sequence is contiguous again.
+// The buffer works on raw payloads so its state can be checkpointed without
+// knowing the decoded types.
+type ReorderBuffer struct {
+	next    int64
+	pending map[int64][]byte
+}
+
+// NewReorderBuffer creates a buffer expecting the given first sequence number
+func NewReorderBuffer(first int64) *ReorderBuffer {
+	return &ReorderBuffer{
+		next:    first,
+		pending: map[int64][]byte{},
+	}
+}
+
+// Push offers a payload with its sequence number and returns the payloads
+// that are now deliverable, in order.
+//
+// Sequence numbers below the expected one (already delivered) are dropped.
+func (buffer *ReorderBuffer) Push(sequence int64, payload []byte) [][]byte {
+	if sequence < buffer.next {
+		return nil
+	}
+	buffer.pending[sequence] = payload
+	var ready [][]byte
+	for {
+		payload, found := buffer.pending[buffer.next]
+		if !found {
+			return ready
+		}
+		delete(buffer.pending, buffer.next)
+		ready = append(ready, payload)
+		buffer.next++
+	}
+}
+
+// Pending gives the number of payloads waiting for their predecessors
+func (buffer *ReorderBuffer) Pending() int {
+	return len(buffer.pending)
+}
+
+// reorderState is the serialized form of a ReorderBuffer
+type reorderState struct {
+	Next   int64             `json:"next"`
+	Parked []reorderedParked `json:"parked"`
+}
+
+type reorderedParked struct {
+	Sequence int64  `json:"sequence"`
+	Payload  []byte `json:"payload"`
+}
+
+// ExportState serializes the buffer so it can be checkpointed across restarts
+func (buffer *ReorderBuffer) ExportState() ([]byte, error) {
+	state := reorderState{Next: buffer.next}
+	for sequence, payload := range buffer.pending {
+		state.Parked = append(state.Parked, reorderedParked{Sequence: sequence, Payload: payload})
+	}
+	sort.Slice(state.Parked, func(i, j int) bool { return state.Parked[i].Sequence < state.Parked[j].Sequence })
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// ImportState restores a buffer from a checkpoint taken with ExportState
+func (buffer *ReorderBuffer) ImportState(payload []byte) error {
+	var state reorderState
+	if err := json.Unmarshal(payload, &state); err != nil {
+		return errors.JSONUnmarshalError.Wrap(err)
+	}
+	buffer.next = state.Next
+	buffer.pending = make(map[int64][]byte, len(state.Parked))
+	for _, parked := range state.Parked {
+		buffer.pending[parked.Sequence] = parked.Payload
+	}
+	return nil
+}
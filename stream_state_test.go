@@ -0,0 +1,91 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestDedupWindowDropsRedeliveries(t *testing.T) {
+	window := argo.NewDedupWindow(10)
+	if window.Seen("a") {
+		t.Error("a should be new")
+	}
+	if !window.Seen("a") {
+		t.Error("a should have been seen")
+	}
+}
+
+func TestDedupWindowEvictsOldestFirst(t *testing.T) {
+	window := argo.NewDedupWindow(2)
+	window.Seen("a")
+	window.Seen("b")
+	window.Seen("c")
+	if window.Seen("a") {
+		t.Error("a should have been evicted")
+	}
+	if !window.Seen("c") {
+		t.Error("c should still be remembered")
+	}
+}
+
+func TestDedupWindowSurvivesCheckpoints(t *testing.T) {
+	window := argo.NewDedupWindow(10)
+	window.Seen("a")
+	window.Seen("b")
+
+	checkpoint, err := window.ExportState()
+	if err != nil {
+		t.Fatalf("failed to export: %s", err)
+	}
+	restored := argo.NewDedupWindow(0)
+	if err := restored.ImportState(checkpoint); err != nil {
+		t.Fatalf("failed to import: %s", err)
+	}
+	if !restored.Seen("a") || !restored.Seen("b") {
+		t.Error("restored window should remember a and b")
+	}
+	if restored.Seen("c") {
+		t.Error("c should be new after restore")
+	}
+}
+
+func TestReorderBufferReleasesInOrder(t *testing.T) {
+	buffer := argo.NewReorderBuffer(1)
+	if ready := buffer.Push(3, []byte("three")); len(ready) != 0 {
+		t.Errorf("3 should be parked, got %d payloads", len(ready))
+	}
+	if ready := buffer.Push(1, []byte("one")); len(ready) != 1 {
+		t.Errorf("1 should be released, got %d payloads", len(ready))
+	}
+	ready := buffer.Push(2, []byte("two"))
+	if len(ready) != 2 || string(ready[0]) != "two" || string(ready[1]) != "three" {
+		t.Errorf("2 and 3 should be released in order, got %q", ready)
+	}
+}
+
+func TestReorderBufferSurvivesCheckpoints(t *testing.T) {
+	buffer := argo.NewReorderBuffer(1)
+	buffer.Push(3, []byte("three"))
+
+	checkpoint, err := buffer.ExportState()
+	if err != nil {
+		t.Fatalf("failed to export: %s", err)
+	}
+	restored := argo.NewReorderBuffer(0)
+	if err := restored.ImportState(checkpoint); err != nil {
+		t.Fatalf("failed to import: %s", err)
+	}
+	if restored.Pending() != 1 {
+		t.Errorf("expected 1 parked payload, got %d", restored.Pending())
+	}
+	buffer.Push(1, []byte("one"))
+	ready := restored.Push(1, []byte("one"))
+	if len(ready) != 1 {
+		t.Errorf("1 should be released after restore, got %d payloads", len(ready))
+	}
+	ready = restored.Push(2, []byte("two"))
+	if len(ready) != 2 {
+		t.Errorf("2 and 3 should be released after restore, got %d payloads", len(ready))
+	}
+}
@@ -0,0 +1,107 @@
+package argo_test
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestCanUnmarshalStreamsConcurrently(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+
+	var stream strings.Builder
+	for i := 0; i < 50; i++ {
+		stream.WriteString(`{"type": "something1", "text": "hello"}` + "\n")
+	}
+	seen := map[int]bool{}
+	for result := range registry.UnmarshalStream(context.Background(), strings.NewReader(stream.String()), 4) {
+		if result.Err != nil {
+			t.Fatalf("element %d failed: %s", result.Index, result.Err)
+		}
+		if _, ok := result.Object.(*Something1); !ok {
+			t.Fatalf("expected a *Something1, got %T", result.Object)
+		}
+		seen[result.Index] = true
+	}
+	if len(seen) != 50 {
+		t.Errorf("expected 50 results, got %d", len(seen))
+	}
+}
+
+func TestUnmarshalStreamCanPreserveOrder(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something2{})
+
+	var stream strings.Builder
+	for i := 0; i < 50; i++ {
+		stream.WriteString(`{"type": "something2", "value": 7}` + "\n")
+	}
+	next := 0
+	for result := range registry.UnmarshalStream(context.Background(), strings.NewReader(stream.String()), 4, argo.WithOrderedResults()) {
+		if result.Err != nil {
+			t.Fatalf("element %d failed: %s", result.Index, result.Err)
+		}
+		if result.Index != next {
+			t.Fatalf("expected index %d, got %d", next, result.Index)
+		}
+		next++
+	}
+	if next != 50 {
+		t.Errorf("expected 50 results, got %d", next)
+	}
+}
+
+func TestUnmarshalStreamReportsElementAndStreamFailures(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	failures := 0
+	for result := range registry.UnmarshalStream(context.Background(), strings.NewReader(`{"type": "bogus"} {"type": "something1", "text": "hi"}`), 2) {
+		if result.Err != nil {
+			failures++
+			if !errors.Is(result.Err, errors.InvalidType) {
+				t.Errorf("error should be an InvalidType, got %s", result.Err)
+			}
+		}
+	}
+	if failures != 1 {
+		t.Errorf("expected 1 failure, got %d", failures)
+	}
+
+	var last argo.Result[Something]
+	for result := range registry.UnmarshalStream(context.Background(), strings.NewReader(`{"type": "something1"} {malformed`), 2, argo.WithOrderedResults()) {
+		last = result
+	}
+	if last.Err == nil {
+		t.Error("the stream should have ended with a failure")
+	}
+}
+
+func TestOrderedStreamWindsDownOnCancel(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+	before := runtime.NumGoroutine()
+
+	for round := 0; round < 5; round++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		var stream strings.Builder
+		for i := 0; i < 200; i++ {
+			stream.WriteString(`{"type": "something1", "text": "hello"}` + "\n")
+		}
+		results := registry.UnmarshalStream(ctx, strings.NewReader(stream.String()), 2, argo.WithOrderedResults())
+		time.Sleep(5 * time.Millisecond) // let the producer back up on a full queue
+		cancel()
+		for range results {
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before+2 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("goroutines leaked after cancel: %d before, %d after", before, runtime.NumGoroutine())
+}
@@ -0,0 +1,120 @@
+package argo
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gildas/argo/core"
+)
+
+// SyncPropertyRegistry is a PropertyRegistry that is safe for concurrent use.
+//
+// Registrations and unmarshals can happen from several goroutines.
+type SyncPropertyRegistry[T core.TypeCarrier] struct {
+	registry *PropertyRegistry[T]
+	lock     sync.RWMutex
+	auditing bool
+	audit    []AuditEntry
+}
+
+// NewSyncPropertyRegistry creates a new SyncPropertyRegistry.
+//
+// The given tags are top-level keys to descend into before matching
+// properties (see NewPropertyRegistry).
+func NewSyncPropertyRegistry[T core.TypeCarrier](tags ...string) *SyncPropertyRegistry[T] {
+	return &SyncPropertyRegistry[T]{
+		registry: NewPropertyRegistry[T](tags...),
+	}
+}
+
+// WithAuditTrail makes the registry record who adds and removes types (see
+// SyncTypeRegistry.WithAuditTrail)
+func (registry *SyncPropertyRegistry[T]) WithAuditTrail() *SyncPropertyRegistry[T] {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.auditing = true
+	return registry
+}
+
+// AuditLog gives a copy of the recorded registration changes, in order
+func (registry *SyncPropertyRegistry[T]) AuditLog() []AuditEntry {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	entries := make([]AuditEntry, len(registry.audit))
+	copy(entries, registry.audit)
+	return entries
+}
+
+// Add adds one or more types to the registry, keyed by their type name
+func (registry *SyncPropertyRegistry[T]) Add(classes ...T) *SyncPropertyRegistry[T] {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.registry.Add(classes...)
+	if registry.auditing {
+		typenames := make([]string, 0, len(classes))
+		for _, class := range classes {
+			typenames = append(typenames, class.GetType())
+		}
+		registry.audit = append(registry.audit, auditEntries("add", typenames, auditCaller(1))...)
+	}
+	return registry
+}
+
+// Remove removes a type from the registry
+func (registry *SyncPropertyRegistry[T]) Remove(typename string) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.registry.Remove(typename)
+	if registry.auditing {
+		registry.audit = append(registry.audit, auditEntries("remove", []string{typename}, auditCaller(1))...)
+	}
+}
+
+// ReplaceAll atomically replaces the whole registration set with the given
+// classes (see SyncTypeRegistry.ReplaceAll)
+func (registry *SyncPropertyRegistry[T]) ReplaceAll(classes ...T) *SyncPropertyRegistry[T] {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	registry.registry.classes = map[string]reflect.Type{}
+	registry.registry.order = nil
+	registry.registry.priorities = map[string]int{}
+	registry.registry.patterns = nil
+	registry.registry.Add(classes...)
+	if registry.auditing {
+		typenames := make([]string, 0, len(classes))
+		for _, class := range classes {
+			typenames = append(typenames, class.GetType())
+		}
+		registry.audit = append(registry.audit, auditEntries("replace", typenames, auditCaller(1))...)
+	}
+	return registry
+}
+
+// Size gives the number of types registered
+func (registry *SyncPropertyRegistry[T]) Size() int {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return registry.registry.Size()
+}
+
+// Types gives the sorted registered type names
+func (registry *SyncPropertyRegistry[T]) Types() []string {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return registry.registry.Types()
+}
+
+// Unmarshal unmarshals the given payload into the type matching one of its properties
+func (registry *SyncPropertyRegistry[T]) Unmarshal(payload []byte) (T, error) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return registry.registry.Unmarshal(payload)
+}
+
+// UnmarshalWithKey unmarshals the given payload like Unmarshal and also
+// gives the property key that matched
+func (registry *SyncPropertyRegistry[T]) UnmarshalWithKey(payload []byte) (T, string, error) {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	return registry.registry.UnmarshalWithKey(payload)
+}
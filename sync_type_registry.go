@@ -0,0 +1,115 @@
+package argo
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gildas/argo/core"
+)
+
+// SyncTypeRegistry is a TypeRegistry that is safe for concurrent use.
+//
+// Registrations are kept behind an atomic pointer to an immutable snapshot:
+// reads (Unmarshal, Size) are lock-free, writes (Add, Remove) serialize on a
+// mutex and swap in a copy-on-write clone. Writes are thus more expensive
+// than on a bare TypeRegistry, reads cost a single atomic load.
+type SyncTypeRegistry[T core.TypeCarrier] struct {
+	snapshot atomic.Pointer[TypeRegistry[T]]
+	write    sync.Mutex
+	auditing bool
+	audit    []AuditEntry
+}
+
+// NewSyncTypeRegistry creates a new SyncTypeRegistry
+func NewSyncTypeRegistry[T core.TypeCarrier](tags ...string) *SyncTypeRegistry[T] {
+	registry := &SyncTypeRegistry[T]{}
+	registry.snapshot.Store(NewTypeRegistry[T](tags...))
+	return registry
+}
+
+// WithAuditTrail makes the registry record who adds and removes types.
+//
+// Every Add and Remove is logged with the caller's file:line and a
+// timestamp, retrievable with AuditLog. This is meant for diagnosing which
+// module registered an unexpected type in a large monolith.
+func (registry *SyncTypeRegistry[T]) WithAuditTrail() *SyncTypeRegistry[T] {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	registry.auditing = true
+	return registry
+}
+
+// AuditLog gives a copy of the recorded registration changes, in order
+func (registry *SyncTypeRegistry[T]) AuditLog() []AuditEntry {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	entries := make([]AuditEntry, len(registry.audit))
+	copy(entries, registry.audit)
+	return entries
+}
+
+// Add adds one or more types to the registry, keyed by their type name
+func (registry *SyncTypeRegistry[T]) Add(classes ...T) *SyncTypeRegistry[T] {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	next := registry.snapshot.Load().clone()
+	next.Add(classes...)
+	registry.snapshot.Store(next)
+	if registry.auditing {
+		typenames := make([]string, 0, len(classes))
+		for _, class := range classes {
+			typenames = append(typenames, class.GetType())
+		}
+		registry.audit = append(registry.audit, auditEntries("add", typenames, auditCaller(1))...)
+	}
+	return registry
+}
+
+// Remove removes a type from the registry, along with its aliases
+func (registry *SyncTypeRegistry[T]) Remove(typename string) {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	next := registry.snapshot.Load().clone()
+	next.Remove(typename)
+	registry.snapshot.Store(next)
+	if registry.auditing {
+		registry.audit = append(registry.audit, auditEntries("remove", []string{typename}, auditCaller(1))...)
+	}
+}
+
+// ReplaceAll atomically replaces the whole registration set with the given
+// classes.
+//
+// Readers see either the previous set or the new one, never a half-updated
+// registry, making this suitable for hot-reloading plugin types. Aliases
+// and versions registered for the previous set are dropped with it.
+func (registry *SyncTypeRegistry[T]) ReplaceAll(classes ...T) *SyncTypeRegistry[T] {
+	registry.write.Lock()
+	defer registry.write.Unlock()
+	next := registry.snapshot.Load().clone()
+	next.replaceAll(classes...)
+	registry.snapshot.Store(next)
+	if registry.auditing {
+		typenames := make([]string, 0, len(classes))
+		for _, class := range classes {
+			typenames = append(typenames, class.GetType())
+		}
+		registry.audit = append(registry.audit, auditEntries("replace", typenames, auditCaller(1))...)
+	}
+	return registry
+}
+
+// Size gives the number of types registered
+func (registry *SyncTypeRegistry[T]) Size() int {
+	return registry.snapshot.Load().Size()
+}
+
+// Types gives the registered type names, in registration order
+func (registry *SyncTypeRegistry[T]) Types() []string {
+	return registry.snapshot.Load().Types()
+}
+
+// Unmarshal unmarshals the given payload into the type matching its discriminator
+func (registry *SyncTypeRegistry[T]) Unmarshal(payload []byte) (T, error) {
+	return registry.snapshot.Load().Unmarshal(payload)
+}
@@ -0,0 +1,67 @@
+package argo_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestSyncTypeRegistryReadsDuringWrites(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]("type").Add(Something1{})
+	payload := []byte(`{"type": "something1", "text": "hello"}`)
+
+	var group sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for iteration := 0; iteration < 100; iteration++ {
+				if _, err := registry.Unmarshal(payload); err != nil {
+					t.Errorf("failed to unmarshal: %s", err)
+					return
+				}
+			}
+		}()
+	}
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		for iteration := 0; iteration < 100; iteration++ {
+			registry.Add(Something2{})
+			registry.Remove("something2")
+		}
+	}()
+	group.Wait()
+
+	if size := registry.Size(); size != 1 {
+		t.Errorf("expected 1 type, got %d", size)
+	}
+}
+
+func TestSyncTypeRegistryCanReplaceAllTypes(t *testing.T) {
+	registry := argo.NewSyncTypeRegistry[Something]("type").Add(Something1{})
+
+	registry.ReplaceAll(Something2{})
+	if size := registry.Size(); size != 1 {
+		t.Errorf("expected 1 type, got %d", size)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1"}`)); err == nil {
+		t.Error("should have failed to unmarshal the replaced type")
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something2", "value": 3}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestSyncPropertyRegistryCanReplaceAllTypes(t *testing.T) {
+	registry := argo.NewSyncPropertyRegistry[Something]().Add(Something1{})
+
+	registry.ReplaceAll(Something2{})
+	if size := registry.Size(); size != 1 {
+		t.Errorf("expected 1 type, got %d", size)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"something2": {"value": 3}}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
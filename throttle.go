@@ -0,0 +1,141 @@
+package argo
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ThrottlePolicy limits how much decoding work a single source can demand
+// from an HTTP handler.
+//
+// Sources are told apart by the Key callback (by default the client IP), and
+// are subject to two independent limits: a fixed-window request quota
+// (Requests per Window) and a concurrent-request ceiling (MaxConcurrent).
+// Breaching either limit answers 429 Too Many Requests before the handler
+// runs, so abusive webhook senders are stopped at the decode layer.
+type ThrottlePolicy struct {
+	// Key tells sources apart, e.g. by IP or API key (default: client IP)
+	Key func(r *http.Request) string
+	// MaxConcurrent caps in-flight requests per source (0: no cap)
+	MaxConcurrent int
+	// Requests caps requests per source and Window (0: no quota)
+	Requests int
+	// Window is the quota window (default: 1 minute)
+	Window time.Duration
+
+	mutex     sync.Mutex
+	sources   map[string]*sourceState
+	lastSweep time.Time
+}
+
+// sourceState tracks one source's current window and in-flight requests
+type sourceState struct {
+	inflight    int
+	count       int
+	windowStart time.Time
+}
+
+// Middleware wraps the given handler with the policy's limits
+func (policy *ThrottlePolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := policy.identify(r)
+		if !policy.admit(key) {
+			w.Header().Set("Retry-After", policy.retryAfter())
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+		defer policy.release(key)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// identify gives the source key of a request
+func (policy *ThrottlePolicy) identify(r *http.Request) string {
+	if policy.Key != nil {
+		return policy.Key(r)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// window gives the quota window, defaulted
+func (policy *ThrottlePolicy) window() time.Duration {
+	if policy.Window > 0 {
+		return policy.Window
+	}
+	return time.Minute
+}
+
+// retryAfter gives the Retry-After header value for rejected requests
+func (policy *ThrottlePolicy) retryAfter() string {
+	seconds := int(policy.window().Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
+
+// admit tells if a request from the given source may proceed, and counts it
+func (policy *ThrottlePolicy) admit(key string) bool {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if policy.sources == nil {
+		policy.sources = map[string]*sourceState{}
+	}
+	policy.sweep()
+	state, found := policy.sources[key]
+	if !found {
+		state = &sourceState{windowStart: time.Now()}
+		policy.sources[key] = state
+	}
+	if elapsed := time.Since(state.windowStart); elapsed >= policy.window() {
+		state.count = 0
+		state.windowStart = time.Now()
+	}
+	if policy.MaxConcurrent > 0 && state.inflight >= policy.MaxConcurrent {
+		return false
+	}
+	if policy.Requests > 0 && state.count >= policy.Requests {
+		return false
+	}
+	state.inflight++
+	state.count++
+	return true
+}
+
+// sweep evicts idle sources whose window expired, at most once per window,
+// so senders rotating keys (e.g. source IPs) cannot grow the map without
+// bound. Callers must hold the mutex.
+func (policy *ThrottlePolicy) sweep() {
+	now := time.Now()
+	if now.Sub(policy.lastSweep) < policy.window() {
+		return
+	}
+	policy.lastSweep = now
+	for key, state := range policy.sources {
+		if state.inflight == 0 && now.Sub(state.windowStart) >= policy.window() {
+			delete(policy.sources, key)
+		}
+	}
+}
+
+// Size gives the number of sources currently tracked
+func (policy *ThrottlePolicy) Size() int {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	return len(policy.sources)
+}
+
+// release lets go of a source's in-flight slot
+func (policy *ThrottlePolicy) release(key string) {
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	if state, found := policy.sources[key]; found {
+		state.inflight--
+	}
+}
@@ -0,0 +1,128 @@
+package argo_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gildas/argo"
+)
+
+func TestThrottleEnforcesRequestQuotas(t *testing.T) {
+	policy := &argo.ThrottlePolicy{Requests: 2, Window: time.Minute}
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	statuses := []int{}
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+		handler.ServeHTTP(recorder, request)
+		statuses = append(statuses, recorder.Code)
+	}
+	if statuses[0] != http.StatusOK || statuses[1] != http.StatusOK {
+		t.Errorf("first two requests should pass, got %v", statuses)
+	}
+	if statuses[2] != http.StatusTooManyRequests {
+		t.Errorf("third request should be throttled, got %v", statuses)
+	}
+}
+
+func TestThrottleTellsSourcesApart(t *testing.T) {
+	policy := &argo.ThrottlePolicy{Requests: 1, Window: time.Minute}
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, addr := range []string{"192.0.2.1:1234", "192.0.2.2:1234"} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.RemoteAddr = addr
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK {
+			t.Errorf("request from %s should pass, got %d", addr, recorder.Code)
+		}
+	}
+}
+
+func TestThrottleCapsConcurrency(t *testing.T) {
+	policy := &argo.ThrottlePolicy{MaxConcurrent: 1}
+	inside := make(chan struct{})
+	release := make(chan struct{})
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(inside)
+		<-release
+	}))
+
+	var group sync.WaitGroup
+	group.Add(1)
+	go func() {
+		defer group.Done()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.RemoteAddr = "192.0.2.1:1234"
+		handler.ServeHTTP(recorder, request)
+	}()
+	<-inside
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.RemoteAddr = "192.0.2.1:5678"
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("concurrent request should be throttled, got %d", recorder.Code)
+	}
+	close(release)
+	group.Wait()
+}
+
+func TestThrottleUsesCustomKeys(t *testing.T) {
+	policy := &argo.ThrottlePolicy{
+		Requests: 1,
+		Key:      func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+	}
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for index, key := range []string{"alpha", "alpha"} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.Header.Set("X-API-Key", key)
+		handler.ServeHTTP(recorder, request)
+		if index == 0 && recorder.Code != http.StatusOK {
+			t.Errorf("first request should pass, got %d", recorder.Code)
+		}
+		if index == 1 && recorder.Code != http.StatusTooManyRequests {
+			t.Errorf("second request should be throttled, got %d", recorder.Code)
+		}
+	}
+}
+
+func TestThrottleEvictsIdleSources(t *testing.T) {
+	policy := &argo.ThrottlePolicy{Requests: 10, Window: 10 * time.Millisecond}
+	handler := policy.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 100; i++ {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		request.RemoteAddr = fmt.Sprintf("192.0.2.%d:1234", i)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	if size := policy.Size(); size != 100 {
+		t.Fatalf("expected 100 tracked sources, got %d", size)
+	}
+	time.Sleep(25 * time.Millisecond)
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.RemoteAddr = "198.51.100.1:1234"
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if size := policy.Size(); size > 1 {
+		t.Errorf("expired sources should have been evicted, still tracking %d", size)
+	}
+}
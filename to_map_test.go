@@ -0,0 +1,64 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+func TestToMapCarriesTheTypeTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	data, err := registry.ToMap(Something1{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["type"] != "something1" {
+		t.Errorf(`expected type "something1", got %v`, data["type"])
+	}
+	if data["text"] != "hello" {
+		t.Errorf(`expected text "hello", got %v`, data["text"])
+	}
+}
+
+func TestToMapOverridesInconsistentTypeTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("kind").Add(Something1{})
+
+	data, err := registry.ToMap(Something1{Type: "bogus", Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["kind"] != "something1" {
+		t.Errorf(`expected kind "something1", got %v`, data["kind"])
+	}
+}
+
+func TestOnMarshalTransformsTheExport(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	err := registry.OnMarshal("something1", func(data map[string]any) map[string]any {
+		data["body"] = data["text"]
+		delete(data, "text")
+		return data
+	})
+	if err != nil {
+		t.Fatalf("failed to register transformer: %s", err)
+	}
+
+	data, err := registry.ToMap(Something1{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to convert: %s", err)
+	}
+	if data["body"] != "hello" {
+		t.Errorf(`expected body "hello", got %v`, data)
+	}
+	if _, found := data["text"]; found {
+		t.Errorf("text should have been renamed, got %v", data)
+	}
+}
+
+func TestOnMarshalRequiresARegisteredType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.OnMarshal("something42", func(data map[string]any) map[string]any { return data }); err == nil {
+		t.Error("should have failed to register transformer")
+	}
+}
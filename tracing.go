@@ -0,0 +1,54 @@
+package argo
+
+import (
+	"context"
+	"fmt"
+)
+
+// Tracer starts spans around decode operations.
+//
+// Implementations adapt a tracing system (OpenTelemetry, Datadog, ...) in a
+// sub-package or in the application; argo never imports tracing modules
+// itself (see ARCHITECTURE.md). The returned context carries the span, so
+// nested operations join the same trace.
+type Tracer interface {
+	// StartSpan starts a span with the given name
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced operation, as started by a Tracer
+type Span interface {
+	// SetAttribute records an attribute on the span
+	SetAttribute(key string, value any)
+	// RecordError records a failure on the span
+	RecordError(err error)
+	// End finishes the span
+	End()
+}
+
+// WithTracer makes the registry start a span around every decode.
+//
+// The span is named "argo.unmarshal" and carries the payload size; on
+// success it also carries the discriminator and the matched Go type, on
+// failure the error is recorded. Spans only join a trace when
+// UnmarshalContext is called with a tracing context.
+func (registry *TypeRegistry[T]) WithTracer(tracer Tracer) *TypeRegistry[T] {
+	registry.tracer = tracer
+	return registry
+}
+
+// traceUnmarshal starts the decode span and gives the completion callback
+// for its outcome
+func (registry *TypeRegistry[T]) traceUnmarshal(ctx context.Context, payload []byte) (context.Context, func(object T, err error)) {
+	ctx, span := registry.tracer.StartSpan(ctx, "argo.unmarshal")
+	span.SetAttribute("payload.size", len(payload))
+	return ctx, func(object T, err error) {
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			span.SetAttribute("discriminator", object.GetType())
+			span.SetAttribute("type", fmt.Sprintf("%T", object))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,74 @@
+package argo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gildas/argo"
+)
+
+// recordingTracer records started spans for assertions
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	name       string
+	attributes map[string]any
+	err        error
+	ended      bool
+}
+
+func (tracer *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, argo.Span) {
+	span := &recordingSpan{name: name, attributes: map[string]any{}}
+	tracer.spans = append(tracer.spans, span)
+	return ctx, span
+}
+
+func (span *recordingSpan) SetAttribute(key string, value any) {
+	span.attributes[key] = value
+}
+
+func (span *recordingSpan) RecordError(err error) {
+	span.err = err
+}
+
+func (span *recordingSpan) End() {
+	span.ended = true
+}
+
+func TestTracerSpansDecodes(t *testing.T) {
+	tracer := &recordingTracer{}
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).WithTracer(tracer)
+
+	if _, err := registry.UnmarshalContext(context.Background(), []byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "bogus"}`)); err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(tracer.spans))
+	}
+	success := tracer.spans[0]
+	if success.name != "argo.unmarshal" {
+		t.Errorf(`expected "argo.unmarshal", got %q`, success.name)
+	}
+	if !success.ended {
+		t.Error("span should have ended")
+	}
+	if success.attributes["discriminator"] != "something1" {
+		t.Errorf("unexpected discriminator: %v", success.attributes["discriminator"])
+	}
+	if success.attributes["payload.size"] == nil {
+		t.Error("span should carry the payload size")
+	}
+	failure := tracer.spans[1]
+	if failure.err == nil {
+		t.Error("span should have recorded the error")
+	}
+	if !failure.ended {
+		t.Error("span should have ended")
+	}
+}
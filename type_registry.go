@@ -0,0 +1,1057 @@
+package argo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// TypeRegistry unmarshals JSON payloads into Go types selected by a discriminator.
+//
+// Types are registered with Add and matched against the value of one of the
+// configured type tags (by default "type"):
+//
+//	{"type": "something1", "text": "hello"}
+//
+// is decoded into the type registered under the name "something1".
+type TypeRegistry[T core.TypeCarrier] struct {
+	tags               []string
+	types              []string
+	classes            map[string]reflect.Type
+	aliases            map[string]string
+	marshalHooks       map[string][]MarshalTransformer
+	failOnTagConflict  bool
+	defaultClass       reflect.Type
+	unknownHandler     func(typename string, payload []byte) (T, error)
+	typeResolver       TypeResolver[T]
+	unmarshalHooks     []func(typename string, object T) error
+	typeUnmarshalHooks map[string][]func(object T) error
+	flights            flightGroup
+	strict             bool
+	useNumber          bool
+	caseInsensitive    bool
+	folded             map[string]string
+	logger             *slog.Logger
+	copyPayloads       bool
+	validateObjects    bool
+	versionTag         string
+	versions           map[string]map[string]reflect.Type
+	defaultVersions    map[string]string
+	migrations         map[string]migration
+	schema             map[string]any
+	frozen             bool
+	maxReportedTypes   int
+	observer           Observer
+	tracer             Tracer
+	codec              Codec
+	backend            JSONBackend
+	checksum           *ChecksumPolicy
+	envelope           *envelopeConfig
+}
+
+// MarshalTransformer reshapes the map representation of an object on its way out
+type MarshalTransformer func(map[string]any) map[string]any
+
+// NewTypeRegistry creates a new TypeRegistry.
+//
+// The given tags are the JSON keys carrying the discriminator, tried in order.
+// Without tags, the registry looks for the key "type". A tag can be a path
+// into nested objects, in dot notation ("metadata.kind") or as a JSON pointer
+// ("/metadata/kind"); a top-level key always wins over a path interpretation.
+func NewTypeRegistry[T core.TypeCarrier](tags ...string) *TypeRegistry[T] {
+	if len(tags) == 0 {
+		tags = []string{"type"}
+	}
+	return &TypeRegistry[T]{
+		tags:               tags,
+		classes:            map[string]reflect.Type{},
+		aliases:            map[string]string{},
+		folded:             map[string]string{},
+		marshalHooks:       map[string][]MarshalTransformer{},
+		typeUnmarshalHooks: map[string][]func(object T) error{},
+	}
+}
+
+// Add adds one or more types to the registry, keyed by their type name.
+//
+// Adding to a frozen registry panics with errors.RegistryFrozen (see Freeze).
+func (registry *TypeRegistry[T]) Add(classes ...T) *TypeRegistry[T] {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	for _, class := range classes {
+		name := class.GetType()
+		registry.types = append(registry.types, name)
+		registry.classes[name] = dereferenceType(reflect.TypeOf(class))
+		registry.folded[strings.ToLower(name)] = name
+		if registry.logger != nil {
+			registry.logger.Debug("registered type", "type", name)
+		}
+	}
+	return registry
+}
+
+// Remove removes a type from the registry, along with its aliases.
+//
+// Removing a type that is not registered is a no-op.
+func (registry *TypeRegistry[T]) Remove(typename string) {
+	if registry.frozen {
+		panic(errors.RegistryFrozen)
+	}
+	if _, found := registry.classes[typename]; !found {
+		return
+	}
+	delete(registry.classes, typename)
+	delete(registry.folded, strings.ToLower(typename))
+	for alias, target := range registry.aliases {
+		if target == typename {
+			delete(registry.aliases, alias)
+			delete(registry.folded, strings.ToLower(alias))
+		}
+	}
+	for index, name := range registry.types {
+		if name == typename {
+			registry.types = append(registry.types[:index], registry.types[index+1:]...)
+			break
+		}
+	}
+	if registry.logger != nil {
+		registry.logger.Debug("removed type", "type", typename)
+	}
+}
+
+// AddAlias registers an alias for an already registered type name.
+//
+// Several discriminator values can thus resolve to the same type, e.g.
+// "msg", "message" and "Message" for the same payload shape. The alias
+// must not collide with a canonical name and the target must be registered.
+func (registry *TypeRegistry[T]) AddAlias(alias string, typename string) error {
+	if registry.frozen {
+		return errors.RegistryFrozen
+	}
+	if _, found := registry.classes[alias]; found {
+		return errors.ArgumentInvalid.With("alias", alias)
+	}
+	if _, found := registry.classes[typename]; !found {
+		return errors.InvalidType.With(typename, registry.types)
+	}
+	registry.aliases[alias] = typename
+	registry.folded[strings.ToLower(alias)] = typename
+	return nil
+}
+
+// AddNumericAlias registers a numeric discriminator value for an already
+// registered type name, for APIs sending e.g. {"type": 3}
+func (registry *TypeRegistry[T]) AddNumericAlias(value int64, typename string) error {
+	return registry.AddAlias(strconv.FormatInt(value, 10), typename)
+}
+
+// WithDefaultType makes Unmarshal decode payloads whose discriminator is not
+// registered into the given type instead of failing.
+//
+// This keeps consumers forward compatible when producers add new event types:
+// unknown payloads land in the default type (typically a catch-all carrying
+// the common fields) instead of being rejected.
+func (registry *TypeRegistry[T]) WithDefaultType(class T) *TypeRegistry[T] {
+	registry.defaultClass = dereferenceType(reflect.TypeOf(class))
+	return registry
+}
+
+// Strict makes Unmarshal reject payloads carrying fields that are not
+// defined on the matched struct (json.Decoder.DisallowUnknownFields),
+// which is useful to validate inbound API contracts.
+func (registry *TypeRegistry[T]) Strict() *TypeRegistry[T] {
+	registry.strict = true
+	return registry
+}
+
+// CopyPayloads makes the registry copy any payload sub-slice it hands to
+// user callbacks (e.g. unknown-type handlers) instead of aliasing the input.
+//
+// Unmarshal itself never retains the payload after it returns, and decoded
+// objects do not alias the input either (encoding/json copies what it
+// keeps). Callbacks are the exception: without this option they receive the
+// caller's buffer. Enable it when decoding from memory-mapped files or
+// reused buffers, where retaining a sub-slice past the call is unsafe.
+func (registry *TypeRegistry[T]) CopyPayloads() *TypeRegistry[T] {
+	registry.copyPayloads = true
+	return registry
+}
+
+// UseNumber makes Unmarshal decode numbers into json.Number instead of
+// float64 (json.Decoder.UseNumber), so large int64 identifiers landing in
+// interface{} fields keep their precision.
+func (registry *TypeRegistry[T]) UseNumber() *TypeRegistry[T] {
+	registry.useNumber = true
+	return registry
+}
+
+// ValidateObjects makes Unmarshal call Validate on decoded objects that
+// implement core.Validatable or core.ValidatableContext, after the
+// post-unmarshal hooks, and fail the decode when validation fails.
+//
+// This replaces the surrogate-unmarshal boilerplate of decoding into a
+// shadow struct just to validate it; validation failures are wrapped in an
+// errors.JSONUnmarshalError.
+func (registry *TypeRegistry[T]) ValidateObjects() *TypeRegistry[T] {
+	registry.validateObjects = true
+	return registry
+}
+
+// validate checks a freshly decoded object's invariants, when the registry
+// is configured to and the object knows how to
+func (registry *TypeRegistry[T]) validate(ctx context.Context, object T) error {
+	if !registry.validateObjects {
+		return nil
+	}
+	switch validatable := any(object).(type) {
+	case core.ValidatableContext:
+		if err := validatable.Validate(ctx); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+	case core.Validatable:
+		if err := validatable.Validate(); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// WithUnknownTypeHandler makes Unmarshal hand payloads with an unregistered
+// discriminator to the given handler instead of failing.
+//
+// The handler receives the discriminator and the raw payload, typically to
+// wrap them in a generic "unknown event" that pipelines can forward or
+// dead-letter. When both an unknown-type handler and a default type are
+// configured, the handler wins.
+func (registry *TypeRegistry[T]) WithUnknownTypeHandler(handler func(typename string, payload []byte) (T, error)) *TypeRegistry[T] {
+	registry.unknownHandler = handler
+	return registry
+}
+
+// FailOnTagConflict makes Unmarshal fail when several configured type tags are
+// present in a payload with different discriminator values.
+//
+// By default the first configured tag that is present wins and the others are
+// ignored.
+func (registry *TypeRegistry[T]) FailOnTagConflict() *TypeRegistry[T] {
+	registry.failOnTagConflict = true
+	return registry
+}
+
+// OnMarshal registers a transformer applied to the map representation of the
+// given type on the ToMap/export path.
+//
+// Transformers run after the type tag is injected, in registration order, so
+// outbound representation tweaks (renaming a field for one partner) live in
+// the registry instead of forked structs.
+func (registry *TypeRegistry[T]) OnMarshal(typename string, transformer MarshalTransformer) error {
+	if _, found := registry.classes[typename]; !found {
+		return errors.InvalidType.With(typename, registry.types)
+	}
+	registry.marshalHooks[typename] = append(registry.marshalHooks[typename], transformer)
+	return nil
+}
+
+// OnUnmarshal registers a hook invoked after every successful decode.
+//
+// Hooks run in registration order and receive the canonical type name along
+// with the decoded object, so normalization (lowercasing IDs, filling
+// computed fields) lives in the registry instead of a custom UnmarshalJSON
+// on every struct. A hook failure fails the decode, wrapped in an
+// errors.JSONUnmarshalError.
+func (registry *TypeRegistry[T]) OnUnmarshal(hook func(typename string, object T) error) *TypeRegistry[T] {
+	registry.unmarshalHooks = append(registry.unmarshalHooks, hook)
+	return registry
+}
+
+// OnUnmarshalType registers a hook invoked after every successful decode of
+// the given type (see OnUnmarshal). The type must already be registered.
+func (registry *TypeRegistry[T]) OnUnmarshalType(typename string, hook func(object T) error) error {
+	if _, found := registry.classes[typename]; !found {
+		return errors.InvalidType.With(typename, registry.types)
+	}
+	registry.typeUnmarshalHooks[typename] = append(registry.typeUnmarshalHooks[typename], hook)
+	return nil
+}
+
+// applyUnmarshalHooks runs the registry-wide and per-type hooks on a freshly
+// decoded object
+func (registry *TypeRegistry[T]) applyUnmarshalHooks(typename string, object T) error {
+	for _, hook := range registry.unmarshalHooks {
+		if err := hook(typename, object); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	for _, hook := range registry.typeUnmarshalHooks[typename] {
+		if err := hook(object); err != nil {
+			return errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Size gives the number of types registered
+func (registry *TypeRegistry[T]) Size() int {
+	return len(registry.classes)
+}
+
+// Tags gives the property keys the registry dispatches on, in lookup order
+func (registry *TypeRegistry[T]) Tags() []string {
+	tags := make([]string, len(registry.tags))
+	copy(tags, registry.tags)
+	return tags
+}
+
+// Types gives the registered type names, in registration order
+func (registry *TypeRegistry[T]) Types() []string {
+	return append([]string(nil), registry.types...)
+}
+
+// Unmarshal unmarshals the given payload into the type matching its discriminator.
+//
+// Type tags are tried in the order they were given to NewTypeRegistry, the
+// first one present in the payload wins (see FailOnTagConflict to reject
+// payloads carrying conflicting discriminators instead).
+//
+// If the payload carries no type tag, an errors.ArgumentMissing is returned,
+// if the discriminator matches no registered type, an errors.InvalidType is
+// returned. Both are wrapped in an errors.JSONUnmarshalError.
+func (registry *TypeRegistry[T]) Unmarshal(payload []byte) (T, error) {
+	return registry.UnmarshalContext(context.Background(), payload)
+}
+
+// UnmarshalAs unmarshals the given payload directly into the named
+// registered type, skipping discriminator extraction.
+//
+// This suits wire formats carrying the type out of band, e.g. GitHub-style
+// webhooks naming the event in a header. The name goes through the usual
+// alias and case resolution, and the unknown-type fallbacks, post-unmarshal
+// hooks and validation all apply; the payload itself needs no type tag.
+func (registry *TypeRegistry[T]) UnmarshalAs(typename string, payload []byte) (object T, err error) {
+	if len(typename) == 0 {
+		return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With("typename"))
+	}
+	return registry.decodeResolved(context.Background(), typename, payload, nil)
+}
+
+// Detect extracts and validates the payload's discriminator without
+// decoding the struct, so routers can make fast routing and ACL decisions
+// before paying for the full unmarshal.
+//
+// The returned name is canonical (after alias and case resolution). A
+// discriminator that matches no registered type is an errors.InvalidType,
+// unless the registry has an unknown-type fallback (a handler or a default
+// type), in which case the raw name is returned; the type resolver is not
+// consulted. A payload without a discriminator is an errors.ArgumentMissing.
+//
+// Registries configured with a codec or an envelope are detected through
+// those paths, so sniffing across heterogeneous registries works (see
+// CanUnmarshal).
+func (registry *TypeRegistry[T]) Detect(payload []byte) (typename string, err error) {
+	found := false
+	switch {
+	case registry.codec != nil:
+		keys, err := registry.codec.Keys(payload)
+		if err != nil {
+			return "", err
+		}
+		for _, tag := range registry.tags {
+			raw, carried := keys[tag]
+			if !carried {
+				continue
+			}
+			var value any
+			if err := registry.codec.Unmarshal(raw, &value); err != nil {
+				return "", err
+			}
+			if typename, err = discriminatorString(value); err != nil {
+				return "", errors.UnmarshalError.Wrap(err)
+			}
+			found = true
+			break
+		}
+	case registry.envelope != nil:
+		var guts map[string]json.RawMessage
+		if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+			return "", errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+		}
+		raw, carried := guts[registry.envelope.typeField]
+		if !carried {
+			return "", errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(registry.envelope.typeField))
+		}
+		if typename, err = decodeDiscriminator(raw); err != nil {
+			return "", errors.JSONUnmarshalError.Wrap(err)
+		}
+		found = true
+	case registry.canScanDiscriminator():
+		if typename, found, err = scanDiscriminator(payload, registry.tags); err != nil {
+			return "", errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+		}
+	default:
+		var guts map[string]json.RawMessage
+		if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+			return "", errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+		}
+		for _, tag := range registry.tags {
+			raw, carried := findTag(guts, tag)
+			if !carried {
+				continue
+			}
+			if typename, err = decodeDiscriminator(raw); err != nil {
+				return "", errors.JSONUnmarshalError.Wrap(err)
+			}
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+	}
+	if resolved, _, registered := registry.resolve(typename); registered {
+		return resolved, nil
+	}
+	if registry.unknownHandler != nil || registry.defaultClass != nil {
+		return typename, nil
+	}
+	return "", errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(typename, registry.supportedTypes()))
+}
+
+// CanUnmarshal tells whether the payload carries a discriminator this
+// registry knows, for content sniffing across several registries.
+//
+// It is a dry run (see Detect): no struct decoding takes place.
+func (registry *TypeRegistry[T]) CanUnmarshal(payload []byte) bool {
+	_, err := registry.Detect(payload)
+	return err == nil
+}
+
+// UnmarshalWithType unmarshals the given payload like Unmarshal and also
+// gives the discriminator value that was matched, after alias resolution, so
+// routing layers can log and meter by wire-level type without re-parsing.
+func (registry *TypeRegistry[T]) UnmarshalWithType(payload []byte) (object T, typename string, err error) {
+	if object, err = registry.Unmarshal(payload); err != nil {
+		return object, "", err
+	}
+	if scanned, found, err := scanDiscriminator(payload, registry.tags); err == nil && found {
+		if resolved, _, registered := registry.resolve(scanned); registered {
+			return object, resolved, nil
+		}
+		return object, scanned, nil
+	}
+	return object, object.GetType(), nil
+}
+
+// UnmarshalContext unmarshals like Unmarshal, honoring the given context.
+//
+// The context is checked before the payload is parsed, again before the
+// matched type is decoded, and every 64KiB during large decodes, so callers
+// with a deadline give up on large payloads instead of tying up their
+// goroutine. Cancellation failures are wrapped in an
+// errors.JSONUnmarshalError, keeping context.DeadlineExceeded and
+// context.Canceled visible to errors.Is.
+func (registry *TypeRegistry[T]) UnmarshalContext(ctx context.Context, payload []byte) (object T, err error) {
+	var guts map[string]json.RawMessage
+
+	if registry.observer != nil {
+		start := time.Now()
+		defer func() {
+			typename := ""
+			if err == nil {
+				typename = object.GetType()
+			}
+			registry.observer.ObserveUnmarshal(typename, time.Since(start), err)
+		}()
+	}
+	if registry.tracer != nil {
+		var finish func(object T, err error)
+		ctx, finish = registry.traceUnmarshal(ctx, payload)
+		defer func() { finish(object, err) }()
+	}
+	if registry.codec != nil {
+		return registry.unmarshalWithCodec(ctx, registry.codec, payload)
+	}
+	if err = ctx.Err(); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if registry.envelope != nil {
+		object, _, err = registry.unmarshalEnvelope(ctx, payload)
+		return object, err
+	}
+	if registry.canScanDiscriminator() {
+		typename, found, err := scanDiscriminator(payload, registry.tags)
+		if err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+		}
+		if !found {
+			return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+		}
+		return registry.decodeResolved(ctx, typename, payload, nil)
+	}
+	if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+	}
+	if registry.schema != nil {
+		if err = ValidateSchema(registry.schema, payload); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	if registry.checksum != nil {
+		if err = registry.checksum.verify(guts); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	if registry.failOnTagConflict {
+		if err = registry.checkTagConflict(guts); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	if len(registry.migrations) > 0 {
+		if payload, guts, err = registry.migrate(payload, guts); err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+	}
+	for _, tag := range registry.tags {
+		raw, found := findTag(guts, tag)
+		if !found {
+			continue
+		}
+		typename, err := decodeDiscriminator(raw)
+		if err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+		return registry.decodeResolved(ctx, typename, payload, guts)
+	}
+	return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+}
+
+// decodeResolved decodes the payload into the type matching an already
+// extracted discriminator, applying the unknown-type fallbacks,
+// post-unmarshal hooks and validation
+func (registry *TypeRegistry[T]) decodeResolved(ctx context.Context, typename string, payload []byte, guts map[string]json.RawMessage) (object T, err error) {
+	typename, class, found := registry.resolve(typename)
+	if !found && registry.typeResolver != nil {
+		typename, class, found = registry.resolveUnknown(typename)
+	}
+	if !found {
+		if registry.unknownHandler != nil {
+			if registry.copyPayloads {
+				payload = append([]byte(nil), payload...)
+			}
+			object, err = registry.unknownHandler(typename, payload)
+			if err != nil {
+				return object, errors.JSONUnmarshalError.Wrap(err)
+			}
+			if err = registry.applyUnmarshalHooks(typename, object); err != nil {
+				return object, err
+			}
+			return object, registry.validate(ctx, object)
+		}
+		if registry.defaultClass == nil {
+			if registry.logger != nil {
+				registry.logger.Debug("unknown discriminator", "type", typename)
+			}
+			return object, errors.JSONUnmarshalError.Wrap(errors.InvalidType.With(typename, registry.supportedTypes()))
+		}
+		class = registry.defaultClass
+	}
+	if err = ctx.Err(); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if class, err = registry.resolveVersion(guts, typename, class); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	value := reflect.New(class).Interface()
+	if err = registry.decodeContext(ctx, payload, value); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(describeDecodeError(payload, err))
+	}
+	object = asRegistered[T](value)
+	if err = registry.applyUnmarshalHooks(typename, object); err != nil {
+		return object, err
+	}
+	return object, registry.validate(ctx, object)
+}
+
+// canScanDiscriminator reports whether the discriminator can be extracted
+// with a single token-level scan of the payload. Features needing the whole
+// document as a map (schema validation, checksums, tag-conflict detection,
+// migrations, versions), nested tag paths and custom JSON backends keep the
+// map pass.
+func (registry *TypeRegistry[T]) canScanDiscriminator() bool {
+	if registry.schema != nil || registry.checksum != nil || registry.failOnTagConflict ||
+		len(registry.migrations) > 0 || len(registry.versions) > 0 || registry.backend.Unmarshal != nil {
+		return false
+	}
+	for _, tag := range registry.tags {
+		if strings.ContainsAny(tag, "./") {
+			return false
+		}
+	}
+	return true
+}
+
+// scanDiscriminator reads the top level of the payload token by token and
+// gives the value of the first configured type tag it carries.
+//
+// The whole top level is read so a duplicated tag resolves last-wins, exactly
+// like encoding/json resolves it during the struct decode; stopping at the
+// first occurrence would let {"type": "a", "type": "b"} dispatch as "a" while
+// decoding the fields of "b" — a type-confusion vector.
+func scanDiscriminator(payload []byte, tags []string) (string, bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return "", false, err
+	}
+	if token == nil { // a JSON null carries no discriminator
+		return "", false, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return "", false, errors.ArgumentInvalid.With("payload", "not an object")
+	}
+	carried := map[string]string{}
+	for decoder.More() {
+		token, err = decoder.Token()
+		if err != nil {
+			return "", false, err
+		}
+		name, _ := token.(string)
+		matched := false
+		for _, tag := range tags {
+			if name == tag {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return "", false, err
+			}
+			continue
+		}
+		var value any
+		if err = decoder.Decode(&value); err != nil {
+			return "", false, err
+		}
+		typename, err := discriminatorString(value)
+		if err != nil {
+			return "", false, err
+		}
+		carried[name] = typename
+	}
+	for _, tag := range tags {
+		if typename, found := carried[tag]; found {
+			return typename, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// UnmarshalAsserted unmarshals the given payload after checking its
+// discriminator against an out-of-band type assertion.
+//
+// High-security webhooks carry a signed claim naming the event type (e.g. a
+// JWT "evt" claim); verifying the claim against the payload's discriminator
+// prevents type-confusion attacks where an attacker flips the discriminator.
+// The assertion is resolved through the same aliases as the discriminator;
+// a mismatch fails with an errors.TypeMismatch.
+func (registry *TypeRegistry[T]) UnmarshalAsserted(assertedType string, payload []byte) (object T, err error) {
+	var guts map[string]json.RawMessage
+
+	if err = registry.jsonUnmarshal(payload, &guts); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	for _, tag := range registry.tags {
+		raw, found := findTag(guts, tag)
+		if !found {
+			continue
+		}
+		typename, err := decodeDiscriminator(raw)
+		if err != nil {
+			return object, errors.JSONUnmarshalError.Wrap(err)
+		}
+		canonical, _, _ := registry.resolve(typename)
+		asserted, _, _ := registry.resolve(assertedType)
+		if canonical != asserted {
+			return object, errors.JSONUnmarshalError.Wrap(errors.TypeMismatch.With(canonical, asserted))
+		}
+		return registry.Unmarshal(payload)
+	}
+	return object, errors.JSONUnmarshalError.Wrap(errors.ArgumentMissing.With(strings.Join(registry.tags, ", ")))
+}
+
+// UnmarshalFrom reads one document from the given reader and unmarshals it
+// into the type matching its discriminator.
+//
+// HTTP bodies and files can thus be decoded without the caller buffering
+// them first. Trailing data after the first document is left unread.
+func (registry *TypeRegistry[T]) UnmarshalFrom(reader io.Reader) (object T, err error) {
+	var raw json.RawMessage
+
+	if err = json.NewDecoder(reader).Decode(&raw); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return registry.Unmarshal(raw)
+}
+
+// UnmarshalFromContext reads like UnmarshalFrom, honoring the given context
+func (registry *TypeRegistry[T]) UnmarshalFromContext(ctx context.Context, reader io.Reader) (object T, err error) {
+	var raw json.RawMessage
+
+	if err = ctx.Err(); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	if err = json.NewDecoder(&contextReader{ctx: ctx, reader: reader}).Decode(&raw); err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return registry.UnmarshalContext(ctx, raw)
+}
+
+// UnmarshalMap unmarshals a keyed collection of polymorphic objects.
+//
+// The payload must be an object whose values each carry their own
+// discriminator, e.g. {"id1": {"type": "something1", ...}, "id2": {...}};
+// every value is decoded through the registry and the keys are preserved.
+func (registry *TypeRegistry[T]) UnmarshalMap(payload []byte) (map[string]T, error) {
+	var guts map[string]json.RawMessage
+
+	if err := registry.jsonUnmarshal(payload, &guts); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	objects := make(map[string]T, len(guts))
+	for key, raw := range guts {
+		object, err := registry.Unmarshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		objects[key] = object
+	}
+	return objects, nil
+}
+
+// ToMap converts the given object into a map, including its discriminator.
+//
+// The object is serialized through encoding/json, so json tags apply. The
+// first configured type tag is set to the object's type name, overriding
+// any value the serialization produced, so schemaless sinks always receive
+// a present and consistent type tag.
+func (registry *TypeRegistry[T]) ToMap(object T) (map[string]any, error) {
+	return registry.toMap(object, false)
+}
+
+func (registry *TypeRegistry[T]) toMap(object T, useNumber bool) (map[string]any, error) {
+	payload, err := registry.jsonMarshal(object)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	data := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	if useNumber {
+		decoder.UseNumber()
+	}
+	if err = decoder.Decode(&data); err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	setTag(data, registry.tags[0], object.GetType())
+	for _, transformer := range registry.marshalHooks[object.GetType()] {
+		data = transformer(data)
+	}
+	return data, nil
+}
+
+// resolve gives the canonical name and Go type for a discriminator,
+// resolving aliases and, when configured, folding case
+func (registry *TypeRegistry[T]) resolve(typename string) (string, reflect.Type, bool) {
+	if canonical, found := registry.aliases[typename]; found {
+		typename = canonical
+	}
+	if class, found := registry.classes[typename]; found {
+		return typename, class, true
+	}
+	if registry.caseInsensitive {
+		if canonical, found := registry.folded[strings.ToLower(typename)]; found {
+			return canonical, registry.classes[canonical], true
+		}
+	}
+	return typename, nil, false
+}
+
+// clone gives a copy of the registry suitable for copy-on-write use.
+//
+// Every mutable map and slice is copied so mutating the clone leaves the
+// original untouched; configured values (codec, backend, handlers, schema
+// documents) are carried over as is. The clone starts with an empty
+// resolver-flight cache.
+func (registry *TypeRegistry[T]) clone() *TypeRegistry[T] {
+	clone := TypeRegistry[T]{
+		failOnTagConflict: registry.failOnTagConflict,
+		defaultClass:      registry.defaultClass,
+		unknownHandler:    registry.unknownHandler,
+		typeResolver:      registry.typeResolver,
+		strict:            registry.strict,
+		useNumber:         registry.useNumber,
+		caseInsensitive:   registry.caseInsensitive,
+		logger:            registry.logger,
+		copyPayloads:      registry.copyPayloads,
+		validateObjects:   registry.validateObjects,
+		versionTag:        registry.versionTag,
+		schema:            registry.schema,
+		frozen:            registry.frozen,
+		maxReportedTypes:  registry.maxReportedTypes,
+		observer:          registry.observer,
+		tracer:            registry.tracer,
+		codec:             registry.codec,
+		backend:           registry.backend,
+		checksum:          registry.checksum,
+		envelope:          registry.envelope,
+	}
+	clone.tags = append([]string(nil), registry.tags...)
+	clone.types = append([]string(nil), registry.types...)
+	clone.classes = cloneMap(registry.classes)
+	clone.aliases = cloneMap(registry.aliases)
+	clone.folded = cloneMap(registry.folded)
+	clone.marshalHooks = make(map[string][]MarshalTransformer, len(registry.marshalHooks))
+	for typename, hooks := range registry.marshalHooks {
+		clone.marshalHooks[typename] = append([]MarshalTransformer(nil), hooks...)
+	}
+	clone.unmarshalHooks = append([]func(typename string, object T) error(nil), registry.unmarshalHooks...)
+	clone.typeUnmarshalHooks = make(map[string][]func(object T) error, len(registry.typeUnmarshalHooks))
+	for typename, hooks := range registry.typeUnmarshalHooks {
+		clone.typeUnmarshalHooks[typename] = append([]func(object T) error(nil), hooks...)
+	}
+	if registry.versions != nil {
+		clone.versions = make(map[string]map[string]reflect.Type, len(registry.versions))
+		for typename, versions := range registry.versions {
+			clone.versions[typename] = cloneMap(versions)
+		}
+	}
+	clone.defaultVersions = cloneMap(registry.defaultVersions)
+	clone.migrations = cloneMap(registry.migrations)
+	return &clone
+}
+
+// replaceAll drops every registration (types, aliases, versions) and
+// registers the given classes instead, keeping the configuration
+func (registry *TypeRegistry[T]) replaceAll(classes ...T) {
+	registry.types = nil
+	registry.classes = map[string]reflect.Type{}
+	registry.aliases = map[string]string{}
+	registry.folded = map[string]string{}
+	registry.versions = nil
+	registry.defaultVersions = nil
+	registry.Add(classes...)
+}
+
+// cloneMap gives a copy of the given map, keeping a nil map nil
+func cloneMap[K comparable, V any](source map[K]V) map[K]V {
+	if source == nil {
+		return nil
+	}
+	clone := make(map[K]V, len(source))
+	for key, value := range source {
+		clone[key] = value
+	}
+	return clone
+}
+
+// decode unmarshals the payload into the given value, honoring the
+// registry's decoding options
+func (registry *TypeRegistry[T]) decode(payload []byte, value any) error {
+	if registry.backend.Unmarshal != nil && !registry.strict && !registry.useNumber {
+		return registry.backend.Unmarshal(payload, value)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	if registry.strict {
+		decoder.DisallowUnknownFields()
+	}
+	if registry.useNumber {
+		decoder.UseNumber()
+	}
+	return decoder.Decode(value)
+}
+
+// decodeDiscriminator gives the discriminator carried by the given raw value.
+//
+// Strings are used as is, numbers and booleans are matched against registered
+// names through their canonical text form ("3", "2.5", "true"). Objects,
+// arrays and null do not make valid discriminators.
+func decodeDiscriminator(raw json.RawMessage) (string, error) {
+	var value any
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	return discriminatorString(value)
+}
+
+// discriminatorString canonicalizes a decoded discriminator value.
+//
+// Strings are used as is, numbers and booleans are matched against
+// registered names through their canonical text form ("3", "2.5", "true").
+func discriminatorString(value any) (string, error) {
+	switch actual := value.(type) {
+	case string:
+		return actual, nil
+	case json.Number:
+		return actual.String(), nil
+	case int64:
+		return strconv.FormatInt(actual, 10), nil
+	case uint64:
+		return strconv.FormatUint(actual, 10), nil
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(actual), nil
+	default:
+		return "", errors.ArgumentInvalid.With("type", value)
+	}
+}
+
+// findTag resolves a type tag against the given decoded payload.
+//
+// The tag is first tried as a literal top-level key, then as a path into
+// nested objects (dot notation or JSON pointer).
+func findTag(guts map[string]json.RawMessage, tag string) (json.RawMessage, bool) {
+	if raw, found := guts[tag]; found {
+		return raw, true
+	}
+	segments := tagPath(tag)
+	for index, segment := range segments {
+		raw, found := guts[segment]
+		if !found {
+			return nil, false
+		}
+		if index == len(segments)-1 {
+			return raw, true
+		}
+		guts = map[string]json.RawMessage{}
+		if err := json.Unmarshal(raw, &guts); err != nil {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// setTag writes the discriminator at the given tag path, creating
+// intermediate objects as needed
+func setTag(data map[string]any, tag string, value string) {
+	segments := tagPath(tag)
+	for index, segment := range segments {
+		if index == len(segments)-1 {
+			data[segment] = value
+			return
+		}
+		child, found := data[segment].(map[string]any)
+		if !found {
+			child = map[string]any{}
+			data[segment] = child
+		}
+		data = child
+	}
+}
+
+// tagPath splits a type tag into its path segments
+func tagPath(tag string) []string {
+	if strings.HasPrefix(tag, "/") {
+		return strings.Split(strings.TrimPrefix(tag, "/"), "/")
+	}
+	return strings.Split(tag, ".")
+}
+
+// checkTagConflict fails when several configured tags carry different
+// discriminator values in the same payload
+func (registry *TypeRegistry[T]) checkTagConflict(guts map[string]json.RawMessage) error {
+	values := map[string]bool{}
+	for _, tag := range registry.tags {
+		if raw, found := findTag(guts, tag); found {
+			typename, err := decodeDiscriminator(raw)
+			if err != nil {
+				return err
+			}
+			values[typename] = true
+		}
+	}
+	if len(values) > 1 {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return errors.ArgumentInvalid.With(strings.Join(registry.tags, ", "), names)
+	}
+	return nil
+}
+
+// supportedTypes describes the discriminator values the registry accepts,
+// reporting aliases separately from canonical names. The candidates are
+// sorted and deduplicated; WithMaxReportedTypes caps how many are listed.
+func (registry *TypeRegistry[T]) supportedTypes() string {
+	types := registry.capCandidates(registry.types)
+	if len(registry.aliases) == 0 {
+		return types
+	}
+	aliases := make([]string, 0, len(registry.aliases))
+	for alias := range registry.aliases {
+		aliases = append(aliases, alias)
+	}
+	return fmt.Sprintf("%s, aliases: %s", types, registry.capCandidates(aliases))
+}
+
+// capCandidates sorts, deduplicates and, when configured, caps a candidate
+// list for error reporting
+func (registry *TypeRegistry[T]) capCandidates(candidates []string) string {
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	deduped := sorted[:0]
+	for index, candidate := range sorted {
+		if index == 0 || candidate != sorted[index-1] {
+			deduped = append(deduped, candidate)
+		}
+	}
+	if registry.maxReportedTypes > 0 && len(deduped) > registry.maxReportedTypes {
+		return fmt.Sprintf("%v and %d more", deduped[:registry.maxReportedTypes], len(deduped)-registry.maxReportedTypes)
+	}
+	return fmt.Sprintf("%v", deduped)
+}
+
+// WithMaxReportedTypes caps how many candidate type names unknown-type
+// errors list, keeping error messages readable for registries with
+// thousands of types; zero (the default) lists them all
+func (registry *TypeRegistry[T]) WithMaxReportedTypes(max int) *TypeRegistry[T] {
+	registry.maxReportedTypes = max
+	return registry
+}
+
+// dereferenceType gives the type pointed at by the given type, if it is a pointer
+func dereferenceType(class reflect.Type) reflect.Type {
+	if class.Kind() == reflect.Pointer {
+		return class.Elem()
+	}
+	return class
+}
+
+// asRegistered converts a freshly allocated *Struct into the registered type T.
+//
+// T is typically an interface implemented by *Struct, but registries over
+// concrete types are supported as well.
+func asRegistered[T any](value any) T {
+	if object, ok := value.(T); ok {
+		return object
+	}
+	return reflect.ValueOf(value).Elem().Interface().(T)
+}
@@ -0,0 +1,447 @@
+package argo_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/codecs/msgpack"
+	"github.com/gildas/argo/errors"
+)
+
+func TestTypeRegistryCanRegisterTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	if size := registry.Size(); size != 2 {
+		t.Errorf("expected 2 types, got %d", size)
+	}
+}
+
+func TestTypeRegistryCanUnmarshal(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestTypeRegistryCanUnmarshalWithCustomTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("kind").Add(Something2{})
+
+	object, err := registry.Unmarshal([]byte(`{"kind": "something2", "value": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something2 := object.(*Something2); something2.Value != 7 {
+		t.Errorf("expected 7, got %d", something2.Value)
+	}
+}
+
+func TestTypeRegistryShouldFailWithoutTypeTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	_, err := registry.Unmarshal([]byte(`{"text": "hello"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestTypeRegistryShouldFailWithUnknownType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something42"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestTypeRegistryShouldFailWithInvalidJSON(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if _, err := registry.Unmarshal([]byte(`{`)); err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+}
+
+func TestTypeRegistryMatchesNumericDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Legacy{})
+	if err := registry.AddNumericAlias(3, "legacy"); err != nil {
+		t.Fatalf("failed to add numeric alias: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": 3, "code": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Legacy); !ok {
+		t.Errorf("expected a *Legacy, got %T", object)
+	}
+}
+
+func TestTypeRegistryMatchesBooleanDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Legacy{})
+	if err := registry.AddAlias("true", "legacy"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	if _, err := registry.Unmarshal([]byte(`{"type": true, "code": 7}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestTypeRegistryRejectsStructuredDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if _, err := registry.Unmarshal([]byte(`{"type": {"name": "something1"}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestTypeRegistryDecodesEscapedDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddAlias(`some"thing`, "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "some\"thing", "text": "hi"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestDefaultTypeCatchesUnknownDiscriminators(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).WithDefaultType(Legacy{})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "brand-new", "code": 7}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if legacy, ok := object.(*Legacy); !ok || legacy.Code != 7 {
+		t.Errorf("expected a *Legacy with code 7, got %#v", object)
+	}
+}
+
+func TestDefaultTypeDoesNotApplyToMissingTag(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).WithDefaultType(Legacy{})
+
+	if _, err := registry.Unmarshal([]byte(`{"code": 7}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+type UnknownEvent struct {
+	Name    string
+	Payload []byte
+}
+
+func (event UnknownEvent) GetType() string { return "unknown" }
+
+func TestUnknownTypeHandlerCapturesRawPayload(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		WithUnknownTypeHandler(func(typename string, payload []byte) (Something, error) {
+			return &UnknownEvent{Name: typename, Payload: payload}, nil
+		})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "brand-new", "data": 1}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	event, ok := object.(*UnknownEvent)
+	if !ok {
+		t.Fatalf("expected an *UnknownEvent, got %T", object)
+	}
+	if event.Name != "brand-new" || len(event.Payload) == 0 {
+		t.Errorf("expected the discriminator and raw payload, got %+v", event)
+	}
+}
+
+func TestUnknownTypeHandlerWinsOverDefaultType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		WithDefaultType(Legacy{}).
+		WithUnknownTypeHandler(func(typename string, payload []byte) (Something, error) {
+			return &UnknownEvent{Name: typename}, nil
+		})
+
+	object, err := registry.Unmarshal([]byte(`{"type": "brand-new"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*UnknownEvent); !ok {
+		t.Errorf("expected an *UnknownEvent, got %T", object)
+	}
+}
+
+func TestStrictModeRejectsUnknownFields(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).Strict()
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "bogus": true}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+	if _, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal a valid payload: %s", err)
+	}
+}
+
+type Loose struct {
+	Data any `json:"data"`
+}
+
+func (loose Loose) GetType() string { return "loose" }
+
+func TestUseNumberPreservesLargeIntegers(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Loose{}).UseNumber()
+
+	object, err := registry.Unmarshal([]byte(`{"type": "loose", "data": 9007199254740993}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	number, ok := object.(*Loose).Data.(json.Number)
+	if !ok {
+		t.Fatalf("expected a json.Number, got %T", object.(*Loose).Data)
+	}
+	if number.String() != "9007199254740993" {
+		t.Errorf("expected full precision, got %s", number)
+	}
+}
+
+func TestUnmarshalMapPreservesKeys(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+
+	objects, err := registry.UnmarshalMap([]byte(`{
+		"id1": {"type": "something1", "text": "hello"},
+		"id2": {"type": "something2", "value": 7}
+	}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	if something1, ok := objects["id1"].(*Something1); !ok || something1.Text != "hello" {
+		t.Errorf("expected a *Something1 under id1, got %#v", objects["id1"])
+	}
+	if something2, ok := objects["id2"].(*Something2); !ok || something2.Value != 7 {
+		t.Errorf("expected a *Something2 under id2, got %#v", objects["id2"])
+	}
+}
+
+func TestUnmarshalMapFailsOnUndecodableValue(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if _, err := registry.UnmarshalMap([]byte(`{"id1": {"type": "bogus"}}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestCopyPayloadsDetachesCallbackSlices(t *testing.T) {
+	var captured []byte
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}).
+		CopyPayloads().
+		WithUnknownTypeHandler(func(typename string, payload []byte) (Something, error) {
+			captured = payload
+			return &UnknownEvent{Name: typename, Payload: payload}, nil
+		})
+
+	buffer := []byte(`{"type": "brand-new", "data": 1}`)
+	if _, err := registry.Unmarshal(buffer); err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	original := string(captured)
+	for index := range buffer { // the caller reuses its buffer, e.g. an mmap window
+		buffer[index] = 'x'
+	}
+	if string(captured) != original {
+		t.Error("the captured payload should not alias the caller's buffer")
+	}
+}
+
+func TestInvalidTypeErrorListsSortedCandidates(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something2{}, Something1{}, Something2{})
+
+	_, err := registry.Unmarshal([]byte(`{"type": "bogus"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !strings.Contains(err.Error(), "[something1 something2]") {
+		t.Errorf("error should list sorted, deduplicated candidates, got %s", err)
+	}
+}
+
+func TestInvalidTypeErrorCanCapItsCandidates(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{}, Legacy{}).WithMaxReportedTypes(2)
+
+	_, err := registry.Unmarshal([]byte(`{"type": "bogus"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !strings.Contains(err.Error(), "[legacy something1] and 1 more") {
+		t.Errorf("error should cap its candidates, got %s", err)
+	}
+}
+
+func TestTypeRegistryCanGiveMatchedType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}, Something2{})
+	if err := registry.AddAlias("s1", "something1"); err != nil {
+		t.Fatalf("failed to add alias: %s", err)
+	}
+
+	object, typename, err := registry.UnmarshalWithType([]byte(`{"type": "s1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if typename != "something1" {
+		t.Errorf(`expected type "something1", got %q`, typename)
+	}
+
+	if _, typename, err = registry.UnmarshalWithType([]byte(`{"type": "bogus"}`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	} else if typename != "" {
+		t.Errorf("expected no type, got %q", typename)
+	}
+}
+
+func TestCanUnmarshalAsNamedType(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).MustAddAlias("s1", "something1")
+
+	object, err := registry.UnmarshalAs("s1", []byte(`{"text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	something1, ok := object.(*Something1)
+	if !ok {
+		t.Fatalf("expected a *Something1, got %T", object)
+	}
+	if something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+
+	if _, err = registry.UnmarshalAs("bogus", []byte(`{}`)); !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+	if _, err = registry.UnmarshalAs("", []byte(`{}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestCanDetectTypes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{}).MustAddAlias("s1", "something1")
+
+	typename, err := registry.Detect([]byte(`{"type": "s1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to detect: %s", err)
+	}
+	if typename != "something1" {
+		t.Errorf(`expected "something1", got %q`, typename)
+	}
+
+	if _, err = registry.Detect([]byte(`{"text": "hello"}`)); !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+	if _, err = registry.Detect([]byte(`{"type": "bogus"}`)); !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+
+	// unknown discriminators pass when the registry has a fallback
+	registry = registry.WithDefaultType(Legacy{})
+	if typename, err = registry.Detect([]byte(`{"type": "bogus"}`)); err != nil || typename != "bogus" {
+		t.Errorf(`expected "bogus", got %q (%v)`, typename, err)
+	}
+}
+
+func TestCanUnmarshalSniffsPayloads(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").Add(Something1{})
+
+	if !registry.CanUnmarshal([]byte(`{"type": "something1"}`)) {
+		t.Error("a registered type should be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"type": "bogus"}`)) {
+		t.Error("an unknown type should not be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"text": "no discriminator"}`)) {
+		t.Error("a payload without discriminator should not be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"broken"`)) {
+		t.Error("a malformed payload should not be sniffable")
+	}
+}
+
+func TestCanDetectTypesThroughCodecs(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]("type").
+		Add(Something1{}).
+		WithCodec(msgpack.Codec{})
+
+	payload, err := msgpack.Codec{}.Marshal(map[string]any{"type": "something1", "text": "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	typename, err := registry.Detect(payload)
+	if err != nil {
+		t.Fatalf("failed to detect: %s", err)
+	}
+	if typename != "something1" {
+		t.Errorf(`expected "something1", got %q`, typename)
+	}
+	if !registry.CanUnmarshal(payload) {
+		t.Error("a codec payload with a registered type should be sniffable")
+	}
+	if registry.CanUnmarshal([]byte(`{"type": "something1"}`)) {
+		t.Error("a JSON payload should not be sniffable on a codec registry")
+	}
+}
+
+func TestCanDetectTypesThroughEnvelopes(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().
+		Add(Something1{}).
+		WithEnvelope("kind", "payload")
+
+	typename, err := registry.Detect([]byte(`{"kind": "something1", "payload": {"text": "hello"}}`))
+	if err != nil {
+		t.Fatalf("failed to detect: %s", err)
+	}
+	if typename != "something1" {
+		t.Errorf(`expected "something1", got %q`, typename)
+	}
+	if registry.CanUnmarshal([]byte(`{"type": "something1"}`)) {
+		t.Error("a payload without the envelope type field should not be sniffable")
+	}
+}
+
+func TestDuplicatedDiscriminatorsResolveLastWins(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{}, Something2{})
+	payload := []byte(`{"type": "something1", "type": "something2", "value": 7}`)
+
+	object, err := registry.Unmarshal(payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something2); !ok {
+		t.Fatalf("expected a *Something2 (last-wins, like encoding/json), got %T", object)
+	}
+	if _, err := registry.UnmarshalAsserted("something1", payload); !errors.Is(err, errors.TypeMismatch) {
+		t.Errorf("error should be a TypeMismatch, got %s", err)
+	}
+	if object, err := registry.UnmarshalAsserted("something2", payload); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	} else if _, ok := object.(*Something2); !ok {
+		t.Errorf("expected a *Something2, got %T", object)
+	}
+}
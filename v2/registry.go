@@ -0,0 +1,67 @@
+// Package v2 is the next generation argo API surface.
+//
+// Registries are immutable: everything is given at construction time
+// (prototypes and functional options) and no method mutates the registry
+// afterwards, which makes them safe to share without locks. The API is also
+// streaming-first: Decode reads from an io.Reader, Unmarshal from bytes.
+//
+// v2 wraps the v1 registries, so both API surfaces share one behavior and
+// existing TypeRegistry/PropertyRegistry call sites keep compiling while
+// services migrate at their own pace.
+package v2
+
+import (
+	"io"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Registry unmarshals polymorphic payloads into Go types.
+//
+// It is immutable once built and safe for concurrent use.
+type Registry[T core.TypeCarrier] struct {
+	inner *argo.TypeRegistry[T]
+}
+
+// New builds a registry over the given prototypes, configured by options
+func New[T core.TypeCarrier](classes []T, options ...argo.Option) *Registry[T] {
+	inner := argo.NewTypeRegistryWith[T](options...)
+	inner.Add(classes...)
+	return &Registry[T]{inner: inner}
+}
+
+// FromV1 wraps an existing v1 registry.
+//
+// The caller must not mutate the v1 registry afterwards, or the immutability
+// guarantees of this package are lost.
+func FromV1[T core.TypeCarrier](registry *argo.TypeRegistry[T]) *Registry[T] {
+	return &Registry[T]{inner: registry}
+}
+
+// V1 exposes the wrapped v1 registry for call sites that still need it
+func (registry *Registry[T]) V1() *argo.TypeRegistry[T] {
+	return registry.inner
+}
+
+// Size gives the number of types registered
+func (registry *Registry[T]) Size() int {
+	return registry.inner.Size()
+}
+
+// Unmarshal unmarshals the given payload into the type matching its
+// discriminator
+func (registry *Registry[T]) Unmarshal(payload []byte) (T, error) {
+	return registry.inner.Unmarshal(payload)
+}
+
+// Decode reads one document from the given reader and unmarshals it into
+// the type matching its discriminator
+func (registry *Registry[T]) Decode(reader io.Reader) (object T, err error) {
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return object, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return registry.inner.Unmarshal(payload)
+}
@@ -0,0 +1,58 @@
+package v2_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	v2 "github.com/gildas/argo/v2"
+)
+
+type Something interface {
+	GetType() string
+}
+
+type Something1 struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (something Something1) GetType() string {
+	return "something1"
+}
+
+func TestV2RegistryUnmarshals(t *testing.T) {
+	registry := v2.New([]Something{Something1{}}, argo.WithTypeTags("type"))
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestV2RegistryDecodesFromReader(t *testing.T) {
+	registry := v2.New([]Something{Something1{}})
+
+	object, err := registry.Decode(strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to decode: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("expected a *Something1, got %T", object)
+	}
+}
+
+func TestV2RegistryAdaptsV1(t *testing.T) {
+	v1 := argo.NewTypeRegistry[Something]().Add(Something1{})
+	registry := v2.FromV1(v1)
+
+	if registry.Size() != 1 {
+		t.Errorf("expected 1 type, got %d", registry.Size())
+	}
+	if registry.V1() != v1 {
+		t.Error("V1 should give back the wrapped registry")
+	}
+}
@@ -0,0 +1,76 @@
+package argo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+type Checked struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (checked Checked) GetType() string {
+	return "checked"
+}
+
+func (checked Checked) Validate() error {
+	if checked.Text == "" {
+		return errors.ArgumentMissing.With("text")
+	}
+	return nil
+}
+
+type CheckedWithContext struct {
+	Type string `json:"type"`
+}
+
+func (checked CheckedWithContext) GetType() string {
+	return "checkedctx"
+}
+
+func (checked CheckedWithContext) Validate(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func TestValidateObjectsAcceptsValidObjects(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Checked{}).ValidateObjects()
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "checked", "text": "hello"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestValidateObjectsRejectsInvalidObjects(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Checked{}).ValidateObjects()
+
+	_, err := registry.Unmarshal([]byte(`{"type": "checked"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should wrap the validation failure, got %s", err)
+	}
+	if !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+}
+
+func TestValidateObjectsIsOptIn(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Checked{})
+
+	if _, err := registry.Unmarshal([]byte(`{"type": "checked"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
+
+func TestValidateObjectsPassesTheContext(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(CheckedWithContext{}).ValidateObjects()
+
+	if _, err := registry.UnmarshalContext(context.Background(), []byte(`{"type": "checkedctx"}`)); err != nil {
+		t.Errorf("failed to unmarshal: %s", err)
+	}
+}
@@ -0,0 +1,113 @@
+package argo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gildas/argo/errors"
+)
+
+// WithVersionTag changes the JSON key carrying the version discriminator
+// (by default "version")
+func (registry *TypeRegistry[T]) WithVersionTag(tag string) *TypeRegistry[T] {
+	registry.versionTag = tag
+	return registry
+}
+
+// AddVersion registers a struct for a (type, version) pair.
+//
+// Payloads carrying both discriminators select the struct matching the
+// pair:
+//
+//	{"type": "something1", "version": "2", ...}
+//
+// decodes into the struct registered with AddVersion("something1", "2", ...).
+// Payloads without a version field decode into the type's default version
+// (see WithDefaultVersion) or, without one, into the struct registered with
+// Add. The type does not need a prior Add; its first version then doubles
+// as the unversioned fallback.
+func (registry *TypeRegistry[T]) AddVersion(typename string, version string, class T) error {
+	if registry.frozen {
+		return errors.RegistryFrozen
+	}
+	if len(typename) == 0 {
+		return errors.ArgumentMissing.With("typename")
+	}
+	if len(version) == 0 {
+		return errors.ArgumentMissing.With("version")
+	}
+	if _, found := registry.classes[typename]; !found {
+		registry.types = append(registry.types, typename)
+		registry.classes[typename] = dereferenceType(reflect.TypeOf(class))
+		registry.folded[strings.ToLower(typename)] = typename
+	}
+	if registry.versions == nil {
+		registry.versions = map[string]map[string]reflect.Type{}
+	}
+	if registry.versions[typename] == nil {
+		registry.versions[typename] = map[string]reflect.Type{}
+	}
+	registry.versions[typename][version] = dereferenceType(reflect.TypeOf(class))
+	if registry.logger != nil {
+		registry.logger.Debug("registered versioned type", "type", typename, "version", version)
+	}
+	return nil
+}
+
+// WithDefaultVersion selects the version used when a payload of the given
+// type carries no version field. The pair must be registered already.
+func (registry *TypeRegistry[T]) WithDefaultVersion(typename string, version string) error {
+	if _, found := registry.versions[typename][version]; !found {
+		return errors.InvalidType.With(typename+"@"+version, registry.supportedVersions(typename))
+	}
+	if registry.defaultVersions == nil {
+		registry.defaultVersions = map[string]string{}
+	}
+	registry.defaultVersions[typename] = version
+	return nil
+}
+
+// versionTagName gives the JSON key carrying the version discriminator
+func (registry *TypeRegistry[T]) versionTagName() string {
+	if len(registry.versionTag) > 0 {
+		return registry.versionTag
+	}
+	return "version"
+}
+
+// supportedVersions gives the sorted versions registered for a type
+func (registry *TypeRegistry[T]) supportedVersions(typename string) []string {
+	versions := make([]string, 0, len(registry.versions[typename]))
+	for version := range registry.versions[typename] {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// resolveVersion narrows the class for a typename down to the version the
+// payload carries, when versions are registered for it
+func (registry *TypeRegistry[T]) resolveVersion(guts map[string]json.RawMessage, typename string, class reflect.Type) (reflect.Type, error) {
+	overrides, found := registry.versions[typename]
+	if !found {
+		return class, nil
+	}
+	version := registry.defaultVersions[typename]
+	if raw, found := findTag(guts, registry.versionTagName()); found {
+		decoded, err := decodeDiscriminator(raw)
+		if err != nil {
+			return nil, err
+		}
+		version = decoded
+	}
+	if len(version) == 0 {
+		return class, nil
+	}
+	versioned, found := overrides[version]
+	if !found {
+		return nil, errors.InvalidType.With(typename+"@"+version, registry.supportedVersions(typename))
+	}
+	return versioned, nil
+}
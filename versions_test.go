@@ -0,0 +1,99 @@
+package argo_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+// Something1V2 is the version 2 shape of something1
+type Something1V2 struct {
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Text    string `json:"text"`
+	Extra   string `json:"extra"`
+}
+
+func (something Something1V2) GetType() string {
+	return "something1"
+}
+
+func TestAddVersionSelectsByPair(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddVersion("something1", "2", Something1V2{}); err != nil {
+		t.Fatalf("failed to add version: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "2", "text": "hello", "extra": "more"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if v2 := object.(*Something1V2); v2.Extra != "more" {
+		t.Errorf(`expected "more", got %q`, v2.Extra)
+	}
+
+	object, err = registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1); !ok {
+		t.Errorf("payload without version should decode into the base struct, got %T", object)
+	}
+}
+
+func TestAddVersionHonorsDefaultVersion(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddVersion("something1", "2", Something1V2{}); err != nil {
+		t.Fatalf("failed to add version: %s", err)
+	}
+	if err := registry.WithDefaultVersion("something1", "2"); err != nil {
+		t.Fatalf("failed to set default version: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1V2); !ok {
+		t.Errorf("payload without version should decode into the default version, got %T", object)
+	}
+}
+
+func TestAddVersionRejectsUnknownVersions(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+	if err := registry.AddVersion("something1", "2", Something1V2{}); err != nil {
+		t.Fatalf("failed to add version: %s", err)
+	}
+
+	_, err := registry.Unmarshal([]byte(`{"type": "something1", "version": "9"}`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+}
+
+func TestAddVersionWithoutPriorAdd(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]()
+	if err := registry.AddVersion("something1", "2", Something1V2{}); err != nil {
+		t.Fatalf("failed to add version: %s", err)
+	}
+
+	object, err := registry.Unmarshal([]byte(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if _, ok := object.(*Something1V2); !ok {
+		t.Errorf("first version should double as the fallback, got %T", object)
+	}
+}
+
+func TestWithDefaultVersionRequiresARegisteredPair(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if err := registry.WithDefaultVersion("something1", "9"); err == nil {
+		t.Error("should have failed to set default version")
+	}
+}
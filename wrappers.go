@@ -0,0 +1,75 @@
+package argo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/gildas/argo/errors"
+)
+
+// Unmarshal unmarshals the given payload into a value of the given type.
+//
+// The error is nil on success; failures are wrapped in an
+// errors.JSONUnmarshalError. Pointer types work too: Unmarshal[*T]
+// allocates the target while decoding.
+func Unmarshal[T any](payload []byte) (*T, error) {
+	var value T
+
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return &value, nil
+}
+
+// UnmarshalStrict unmarshals like Unmarshal and rejects payloads carrying
+// fields that are not defined on the target type
+// (json.Decoder.DisallowUnknownFields).
+//
+// Failures are wrapped in an errors.JSONUnmarshalError.
+func UnmarshalStrict[T any](payload []byte) (*T, error) {
+	var value T
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return &value, nil
+}
+
+// Marshal marshals the given value into JSON.
+//
+// Failures are wrapped in an errors.JSONMarshalError, so callers get
+// uniform error chains in both directions.
+func Marshal(value any) ([]byte, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// MarshalIndent marshals the given value into indented JSON.
+//
+// Failures are wrapped in an errors.JSONMarshalError.
+func MarshalIndent(value any, prefix string, indent string) ([]byte, error) {
+	payload, err := json.MarshalIndent(value, prefix, indent)
+	if err != nil {
+		return nil, errors.JSONMarshalError.Wrap(err)
+	}
+	return payload, nil
+}
+
+// UnmarshalFrom reads one document from the given reader and unmarshals it
+// into a value of the given type.
+//
+// Failures are wrapped in an errors.JSONUnmarshalError.
+func UnmarshalFrom[T any](reader io.Reader) (*T, error) {
+	var value T
+
+	if err := json.NewDecoder(reader).Decode(&value); err != nil {
+		return nil, errors.JSONUnmarshalError.Wrap(err)
+	}
+	return &value, nil
+}
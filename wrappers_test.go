@@ -0,0 +1,111 @@
+package argo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gildas/argo"
+	"github.com/gildas/argo/errors"
+)
+
+func TestUnmarshalFromReadsTheRegistry(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	object, err := registry.UnmarshalFrom(strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if something1 := object.(*Something1); something1.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, something1.Text)
+	}
+}
+
+func TestUnmarshalFromFailsOnBadDocuments(t *testing.T) {
+	registry := argo.NewTypeRegistry[Something]().Add(Something1{})
+
+	if _, err := registry.UnmarshalFrom(strings.NewReader(`{`)); err == nil {
+		t.Error("should have failed to unmarshal")
+	}
+}
+
+func TestPackageUnmarshalFrom(t *testing.T) {
+	value, err := argo.UnmarshalFrom[Something1](strings.NewReader(`{"type": "something1", "text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if value.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, value.Text)
+	}
+}
+
+func TestPackageMarshal(t *testing.T) {
+	payload, err := argo.Marshal(Something1{Text: "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if string(payload) != `{"type":"","text":"hello"}` {
+		t.Errorf("unexpected payload: %s", payload)
+	}
+
+	if _, err = argo.Marshal(func() {}); !errors.Is(err, errors.JSONMarshalError) {
+		t.Errorf("error should be a JSONMarshalError, got %s", err)
+	}
+}
+
+func TestPackageMarshalIndent(t *testing.T) {
+	payload, err := argo.MarshalIndent(map[string]string{"text": "hello"}, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	if !strings.Contains(string(payload), "\n  \"text\": \"hello\"") {
+		t.Errorf("unexpected payload: %s", payload)
+	}
+
+	if _, err = argo.MarshalIndent(func() {}, "", "  "); !errors.Is(err, errors.JSONMarshalError) {
+		t.Errorf("error should be a JSONMarshalError, got %s", err)
+	}
+}
+
+func TestPackageUnmarshalReturnsNilOnSuccess(t *testing.T) {
+	value, err := argo.Unmarshal[Something1]([]byte(`{"text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if value.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, value.Text)
+	}
+
+	if _, err = argo.Unmarshal[Something1]([]byte(`{`)); !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+}
+
+func TestPackageUnmarshalHandlesPointerTargets(t *testing.T) {
+	value, err := argo.Unmarshal[*Something1]([]byte(`{"text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if (*value).Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, (*value).Text)
+	}
+}
+
+func TestPackageUnmarshalStrict(t *testing.T) {
+	if _, err := argo.UnmarshalStrict[Something1]([]byte(`{"text": "hello", "bogus": 1}`)); !errors.Is(err, errors.JSONUnmarshalError) {
+		t.Errorf("error should be a JSONUnmarshalError, got %s", err)
+	}
+	value, err := argo.UnmarshalStrict[Something1]([]byte(`{"text": "hello"}`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if value.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, value.Text)
+	}
+}
+
+func TestPackageMustUnmarshal(t *testing.T) {
+	value := argo.MustUnmarshal[Something1]([]byte(`{"text": "hello"}`))
+	if value.Text != "hello" {
+		t.Errorf(`expected "hello", got %q`, value.Text)
+	}
+}
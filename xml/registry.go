@@ -0,0 +1,122 @@
+// Package xml brings registry-style dispatch to XML payloads.
+//
+// SOAP-ish and legacy XML feeds rarely carry a JSON-like discriminator
+// field; instead the root element's name (or one of its attributes) tells
+// the types apart. This registry keys off that name and decodes the
+// document through encoding/xml:
+//
+//	<user-created><id>U1</id></user-created>
+//
+// is decoded into the type registered under "user-created".
+package xml
+
+import (
+	"bytes"
+	stdxml "encoding/xml"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+)
+
+// Registry unmarshals XML payloads into Go types selected by their root
+// element name or, with WithAttribute, by a root attribute
+type Registry[T core.TypeCarrier] struct {
+	classes   map[string]reflect.Type
+	attribute string
+}
+
+// NewRegistry creates a new Registry
+func NewRegistry[T core.TypeCarrier]() *Registry[T] {
+	return &Registry[T]{
+		classes: map[string]reflect.Type{},
+	}
+}
+
+// Add adds one or more types to the registry, keyed by their type name
+func (registry *Registry[T]) Add(classes ...T) *Registry[T] {
+	for _, class := range classes {
+		registry.classes[class.GetType()] = dereference(reflect.TypeOf(class))
+	}
+	return registry
+}
+
+// WithAttribute makes the registry dispatch on the given root attribute
+// instead of the root element name:
+//
+//	<envelope kind="user-created">...</envelope>
+func (registry *Registry[T]) WithAttribute(name string) *Registry[T] {
+	registry.attribute = name
+	return registry
+}
+
+// Size gives the number of types registered
+func (registry *Registry[T]) Size() int {
+	return len(registry.classes)
+}
+
+// Unmarshal unmarshals the given document into the type matching its root.
+//
+// If the discriminator matches no registered type, an errors.InvalidType is
+// returned; if the configured attribute is absent, an errors.ArgumentMissing.
+// Both are wrapped in an errors.XMLUnmarshalError.
+func (registry *Registry[T]) Unmarshal(payload []byte) (object T, err error) {
+	decoder := stdxml.NewDecoder(bytes.NewReader(payload))
+	start, err := rootOf(decoder)
+	if err != nil {
+		return object, errors.XMLUnmarshalError.Wrap(err)
+	}
+	discriminator := start.Name.Local
+	if len(registry.attribute) > 0 {
+		discriminator = ""
+		for _, attribute := range start.Attr {
+			if attribute.Name.Local == registry.attribute {
+				discriminator = attribute.Value
+				break
+			}
+		}
+		if len(discriminator) == 0 {
+			return object, errors.XMLUnmarshalError.Wrap(errors.ArgumentMissing.With(registry.attribute))
+		}
+	}
+	class, found := registry.classes[discriminator]
+	if !found {
+		names := make([]string, 0, len(registry.classes))
+		for name := range registry.classes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return object, errors.XMLUnmarshalError.Wrap(errors.InvalidType.With(discriminator, names))
+	}
+	value := reflect.New(class).Interface()
+	if err = decoder.DecodeElement(value, &start); err != nil {
+		return object, errors.XMLUnmarshalError.Wrap(err)
+	}
+	return value.(T), nil
+}
+
+// rootOf reads tokens up to the document's root element
+func rootOf(decoder *stdxml.Decoder) (stdxml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return stdxml.StartElement{}, errors.ArgumentMissing.With("root element")
+			}
+			return stdxml.StartElement{}, err
+		}
+		if start, ok := token.(stdxml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// dereference gives the value type behind a pointer type
+func dereference(class reflect.Type) reflect.Type {
+	for class.Kind() == reflect.Pointer {
+		class = class.Elem()
+	}
+	return class
+}
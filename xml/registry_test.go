@@ -0,0 +1,80 @@
+package xml_test
+
+import (
+	"testing"
+
+	"github.com/gildas/argo/core"
+	"github.com/gildas/argo/errors"
+	"github.com/gildas/argo/xml"
+)
+
+type Record interface {
+	core.TypeCarrier
+}
+
+type UserCreated struct {
+	ID string `xml:"id"`
+}
+
+func (record UserCreated) GetType() string {
+	return "user-created"
+}
+
+type UserDeleted struct {
+	ID string `xml:"id"`
+}
+
+func (record UserDeleted) GetType() string {
+	return "user-deleted"
+}
+
+func TestXMLDispatchesOnRootElement(t *testing.T) {
+	registry := xml.NewRegistry[Record]().Add(UserCreated{}, UserDeleted{})
+
+	object, err := registry.Unmarshal([]byte(`<user-created><id>U1</id></user-created>`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if created := object.(*UserCreated); created.ID != "U1" {
+		t.Errorf(`expected "U1", got %q`, created.ID)
+	}
+}
+
+func TestXMLDispatchesOnAttribute(t *testing.T) {
+	registry := xml.NewRegistry[Record]().Add(UserCreated{}).WithAttribute("kind")
+
+	object, err := registry.Unmarshal([]byte(`<envelope kind="user-created"><id>U1</id></envelope>`))
+	if err != nil {
+		t.Fatalf("failed to unmarshal: %s", err)
+	}
+	if created := object.(*UserCreated); created.ID != "U1" {
+		t.Errorf(`expected "U1", got %q`, created.ID)
+	}
+}
+
+func TestXMLRequiresItsAttribute(t *testing.T) {
+	registry := xml.NewRegistry[Record]().Add(UserCreated{}).WithAttribute("kind")
+
+	_, err := registry.Unmarshal([]byte(`<envelope><id>U1</id></envelope>`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.ArgumentMissing) {
+		t.Errorf("error should be an ArgumentMissing, got %s", err)
+	}
+}
+
+func TestXMLFailsOnUnknownRoots(t *testing.T) {
+	registry := xml.NewRegistry[Record]().Add(UserCreated{})
+
+	_, err := registry.Unmarshal([]byte(`<whatever/>`))
+	if err == nil {
+		t.Fatal("should have failed to unmarshal")
+	}
+	if !errors.Is(err, errors.InvalidType) {
+		t.Errorf("error should be an InvalidType, got %s", err)
+	}
+	if !errors.Is(err, errors.XMLUnmarshalError) {
+		t.Errorf("error should be an XMLUnmarshalError, got %s", err)
+	}
+}